@@ -0,0 +1,296 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Aggregation 聚合列，由 CountAll / Count / CountDistinct / Sum / Avg / Min / Max 创建
+type Aggregation struct {
+	fn       string
+	column   string
+	distinct bool
+	alias    string
+}
+
+// CountAll COUNT(*)，默认结果列名为 count
+func CountAll() Aggregation { return Aggregation{fn: "COUNT", column: "*"} }
+
+// Count COUNT(column)，不统计 NULL，默认结果列名为 count_<column>
+func Count(column string) Aggregation { return Aggregation{fn: "COUNT", column: column} }
+
+// CountDistinct COUNT(DISTINCT column)，默认结果列名为 count_distinct_<column>
+func CountDistinct(column string) Aggregation {
+	return Aggregation{fn: "COUNT", column: column, distinct: true}
+}
+
+// Sum SUM(column)，默认结果列名为 sum_<column>
+func Sum(column string) Aggregation { return Aggregation{fn: "SUM", column: column} }
+
+// Avg AVG(column)，默认结果列名为 avg_<column>
+func Avg(column string) Aggregation { return Aggregation{fn: "AVG", column: column} }
+
+// Min MIN(column)，默认结果列名为 min_<column>
+func Min(column string) Aggregation { return Aggregation{fn: "MIN", column: column} }
+
+// Max MAX(column)，默认结果列名为 max_<column>
+func Max(column string) Aggregation { return Aggregation{fn: "MAX", column: column} }
+
+// As sets the result column name, which is also usable in Having and SortKeys.
+func (a Aggregation) As(alias string) Aggregation {
+	a.alias = alias
+	return a
+}
+
+// name 返回结果列名
+func (a Aggregation) name() string {
+	if a.alias != "" {
+		return a.alias
+	}
+	name := strings.ToLower(a.fn)
+	if a.column == "*" {
+		return name
+	}
+	if a.distinct {
+		name += "_distinct"
+	}
+	return name + "_" + keyColumn(a.column)
+}
+
+// expr 渲染聚合表达式 (不含别名)
+func (a Aggregation) expr(dialect Dialect) (string, error) {
+	if a.fn == "" {
+		return "", errors.New("empty aggregation")
+	}
+	if a.column != "*" {
+		if err := validateIdentifier(a.column); err != nil {
+			return "", err
+		}
+	}
+	if name := a.name(); validateIdentifier(name) != nil || strings.Contains(name, ".") {
+		return "", fmt.Errorf("invalid aggregation alias %q", name)
+	}
+	distinct := ""
+	if a.distinct {
+		distinct = "DISTINCT "
+	}
+	return fmt.Sprintf("%s(%s%s)", a.fn, distinct, quoteIdent(dialect, a.column)), nil
+}
+
+// aggregateExprs 返回 别名 -> 聚合表达式，供 HAVING 使用
+func (s AggregateEndPoint[T]) aggregateExprs(dialect Dialect) (map[string]string, error) {
+	exprs := make(map[string]string, len(s.Aggregates))
+	for _, a := range s.Aggregates {
+		expr, err := a.expr(dialect)
+		if err != nil {
+			return nil, err
+		}
+		exprs[a.name()] = expr
+	}
+	return exprs, nil
+}
+
+// clauses 构建 FROM ... WHERE ... GROUP BY ... HAVING ... 部分
+func (s AggregateEndPoint[T]) clauses(dialect Dialect) (string, []any, error) {
+	exprs, err := s.aggregateExprs(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	tableQuery, args, err := buildFromClause(dialect, s.Table, s.Joins)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("FROM ")
+	b.WriteString(tableQuery)
+
+	conditionsQuery, conditionsArgs, err := buildWhereClause(dialect, s.Conditions)
+	if err != nil {
+		return "", nil, err
+	}
+	if conditionsQuery != "" {
+		b.WriteString(" ")
+		b.WriteString(conditionsQuery)
+		args = append(args, conditionsArgs...)
+	}
+
+	for _, g := range s.GroupBy {
+		if err := validateIdentifier(g); err != nil {
+			return "", nil, err
+		}
+	}
+	if len(s.GroupBy) > 0 {
+		b.WriteString(" GROUP BY ")
+		b.WriteString(strings.Join(quoteIdents(dialect, s.GroupBy), ", "))
+	}
+
+	havingQuery, havingArgs, err := buildConditionWith(dialect, s.Having, exprs)
+	if err != nil {
+		return "", nil, err
+	}
+	if havingQuery != "" {
+		b.WriteString(" HAVING ")
+		b.WriteString(havingQuery)
+		args = append(args, havingArgs...)
+	}
+	return b.String(), args, nil
+}
+
+func (s AggregateEndPoint[T]) point2Sql(dialect Dialect) (string, []any, error) {
+	if len(s.Aggregates) == 0 && len(s.GroupBy) == 0 {
+		return "", nil, errors.New("empty aggregates and group by")
+	}
+	columns := make([]string, 0, len(s.GroupBy)+len(s.Aggregates))
+	columns = append(columns, quoteIdents(dialect, s.GroupBy)...)
+	for _, a := range s.Aggregates {
+		expr, err := a.expr(dialect)
+		if err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, fmt.Sprintf("%s AS %s", expr, dialect.Quote(a.name())))
+	}
+
+	clauses, args, err := s.clauses(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString(fmt.Sprintf("SELECT %v %v", strings.Join(columns, ","), clauses))
+
+	if len(s.SortKeys) > 0 {
+		orderBy, err := buildOrderByClause(dialect, s.SortKeys)
+		if err != nil {
+			return "", nil, err
+		}
+		queryBuilder.WriteString(" ")
+		queryBuilder.WriteString(orderBy)
+	}
+	if s.Limit > 0 {
+		queryBuilder.WriteString(" ")
+		queryBuilder.WriteString(dialect.LimitOffset(int64(s.Limit), 0, len(s.SortKeys) > 0))
+	}
+	return queryBuilder.String(), args, nil
+}
+
+// point2CountSql 统计匹配的行数，有 GROUP BY / HAVING 时统计分组数
+func (s AggregateEndPoint[T]) point2CountSql(dialect Dialect) (string, []any, error) {
+	clauses, args, err := s.clauses(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(s.GroupBy) == 0 && s.Having == nil {
+		return "SELECT COUNT(*) " + clauses, args, nil
+	}
+	return fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 AS one %s) AS t", clauses), args, nil
+}
+
+// point2ExistsSql 最多读取一行，结果为 0 或 1；不用 EXISTS 是因为 SQL Server 不支持 SELECT EXISTS(...)
+func (s AggregateEndPoint[T]) point2ExistsSql(dialect Dialect) (string, []any, error) {
+	clauses, args, err := s.clauses(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 AS one %s %s) AS t", clauses, dialect.LimitOffset(1, 0, false)), args, nil
+}
+
+// aggregateEndpoint 补全表名、校验列并追加软删除过滤
+func (d *DAO[T]) aggregateEndpoint(endpoint AggregateEndPoint[T]) (AggregateEndPoint[T], error) {
+	endpoint.Table = d.table(endpoint.Table)
+	refs := columnRefs{
+		fields:     endpoint.GroupBy,
+		sortKeys:   endpoint.SortKeys,
+		conditions: endpoint.Conditions,
+		joins:      endpoint.Joins,
+		having:     endpoint.Having,
+	}
+	for _, a := range endpoint.Aggregates {
+		if a.column != "*" {
+			refs.fields = append(slices.Clip(refs.fields), a.column)
+		}
+		refs.aliases = append(refs.aliases, a.name())
+	}
+	if err := d.checkColumns(refs); err != nil {
+		return endpoint, err
+	}
+	conditions, err := d.readConditions(endpoint.Table, tableQualifier(endpoint.Table, endpoint.Joins), endpoint.Conditions)
+	if err != nil {
+		return endpoint, err
+	}
+	endpoint.Conditions = conditions
+	endpoint.Having = redactCondition(d.sensitiveSet(endpoint.Table), endpoint.Having)
+	return endpoint, nil
+}
+
+// Count returns the number of rows matching endpoint.Table, Joins and Conditions,
+// or the number of groups when GroupBy or Having is set. Aggregates, SortKeys and Limit are ignored.
+func (d *DAO[T]) Count(ctx context.Context, endpoint AggregateEndPoint[T]) (int64, error) {
+	endpoint, err := d.aggregateEndpoint(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err := endpoint.point2CountSql(d.cfg.dialect)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	if err := d.getContext(ctx, "Count", endpoint.Table, &total, query, args); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// Exists reports whether any row (or group, when GroupBy or Having is set) matches the endpoint.
+// It stops at the first match instead of counting all rows.
+func (d *DAO[T]) Exists(ctx context.Context, endpoint AggregateEndPoint[T]) (bool, error) {
+	endpoint, err := d.aggregateEndpoint(endpoint)
+	if err != nil {
+		return false, err
+	}
+	query, args, err := endpoint.point2ExistsSql(d.cfg.dialect)
+	if err != nil {
+		return false, err
+	}
+	var n int64
+	if err := d.getContext(ctx, "Exists", endpoint.Table, &n, query, args); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Aggregate runs an aggregate query and scans each result row into R, whose db tags name the
+// GroupBy columns (without table prefix) and the aggregation aliases. R may also be a scalar type
+// when a single column is selected, e.g. Aggregate[float64] with one Sum and no GroupBy.
+//
+//	type statusTotal struct {
+//		Status string  `db:"status"`
+//		Total  float64 `db:"total"`
+//	}
+//	rows, err := db_dao.Aggregate[statusTotal](ctx, orderDAO, db_dao.AggregateEndPoint[Order]{
+//		Aggregates: []db_dao.Aggregation{db_dao.Sum("amount").As("total")},
+//		GroupBy:    []string{"status"},
+//		Having:     map[string]any{"total": db_dao.Gt(100)},
+//	})
+func Aggregate[R, T any](ctx context.Context, dao IDAO[T], endpoint AggregateEndPoint[T]) ([]R, error) {
+	d, err := daoOf(dao)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err = d.aggregateEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := endpoint.point2Sql(d.cfg.dialect)
+	if err != nil {
+		return nil, err
+	}
+	var rows []R
+	if err := d.selectContext(ctx, "Aggregate", endpoint.Table, &rows, query, args); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,151 @@
+package db_dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- aggregate_test.go: Tests for aggregate queries ---
+
+func TestAggregateEndPointSQL(t *testing.T) {
+	ep := AggregateEndPoint[struct{}]{
+		Table:      "orders",
+		Conditions: map[string]any{"status": Ne("void")},
+		Aggregates: []Aggregation{CountAll(), Sum("amount").As("total"), CountDistinct("user_id")},
+		GroupBy:    []string{"status"},
+		Having:     map[string]any{"total": Gt(100), "count": Gte(2)},
+		SortKeys:   []SortKey{{Column: "total", Desc: true}},
+		Limit:      5,
+	}
+	query, args, err := ep.ToSQL(Postgres)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "status",COUNT(*) AS "count",SUM("amount") AS "total",COUNT(DISTINCT "user_id") AS "count_distinct_user_id" FROM "orders" WHERE ("status" <> $1) GROUP BY "status" HAVING (COUNT(*) >= $2) AND (SUM("amount") > $3) ORDER BY "total" DESC LIMIT 5 OFFSET 0`, query)
+	assert.Equal(t, []any{"void", 2, 100}, args)
+
+	query, args, err = ep.point2CountSql(SQLite)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT 1 AS one FROM `orders` WHERE (`status` <> ?) GROUP BY `status` HAVING (COUNT(*) >= ?) AND (SUM(`amount`) > ?)) AS t", query)
+	assert.Equal(t, []any{"void", 2, 100}, args)
+
+	query, _, err = AggregateEndPoint[struct{}]{Table: "orders"}.point2CountSql(SQLite)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM `orders`", query)
+
+	query, args, err = AggregateEndPoint[struct{}]{Table: "orders", Conditions: map[string]any{"id": Eq(1)}}.point2ExistsSql(SQLServer)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(*) FROM (SELECT 1 AS one FROM [orders] WHERE ([id] = ?) ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY) AS t`, query)
+	assert.Equal(t, []any{1}, args)
+
+	explained, err := AggregateEndPoint[struct{}]{Table: "orders", Aggregates: []Aggregation{Max("o.amount")}}.Explain(nil)
+	require.NoError(t, err)
+	assert.Equal(t, explainHeader+"SELECT MAX(`o`.`amount`) AS `max_amount` FROM `orders`", explained)
+
+	_, _, err = AggregateEndPoint[struct{}]{Table: "orders"}.ToSQL(nil)
+	assert.EqualError(t, err, "empty aggregates and group by")
+	_, _, err = AggregateEndPoint[struct{}]{Table: "orders", Aggregates: []Aggregation{Sum("amount); DROP TABLE orders; --")}}.ToSQL(nil)
+	assert.Error(t, err)
+	_, _, err = AggregateEndPoint[struct{}]{Table: "orders", Aggregates: []Aggregation{Sum("amount").As("a b")}}.ToSQL(nil)
+	assert.EqualError(t, err, `invalid aggregation alias "a b"`)
+	_, _, err = AggregateEndPoint[struct{}]{Table: "orders", GroupBy: []string{"status; --"}}.ToSQL(nil)
+	assert.Error(t, err)
+}
+
+func TestAggregateColumnValidation(t *testing.T) {
+	dao := NewDAO[User](nil, WithColumnValidation())
+	_, err := dao.aggregateEndpoint(AggregateEndPoint[User]{
+		Aggregates: []Aggregation{Avg("age").As("avg_age")},
+		GroupBy:    []string{"name"},
+		Having:     map[string]any{"avg_age": Gt(18)},
+		SortKeys:   []SortKey{{Column: "avg_age"}},
+	})
+	assert.NoError(t, err)
+
+	_, err = dao.aggregateEndpoint(AggregateEndPoint[User]{Aggregates: []Aggregation{Sum("secret")}})
+	var colErr *ColumnError
+	require.ErrorAs(t, err, &colErr)
+	assert.Equal(t, "secret", colErr.Column)
+
+	_, err = dao.aggregateEndpoint(AggregateEndPoint[User]{Aggregates: []Aggregation{CountAll()}, Having: map[string]any{"secret": Gt(1)}})
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+}
+
+type ageGroup struct {
+	Age   int     `db:"age"`
+	Users int64   `db:"users"`
+	Avg   float64 `db:"avg_id"`
+}
+
+func (s *DAOTestSuite) TestAggregate() {
+	ctx := context.Background()
+	_, err := s.db.Exec(`INSERT INTO users (id, name, age) VALUES (3, 'Carol', 30)`)
+	s.Require().NoError(err)
+
+	count, err := s.userDAO.Count(ctx, AggregateEndPoint[User]{})
+	s.Require().NoError(err)
+	s.Equal(int64(3), count)
+
+	count, err = s.userDAO.Count(ctx, AggregateEndPoint[User]{Conditions: map[string]any{"age": Gt(30)}})
+	s.Require().NoError(err)
+	s.Equal(int64(1), count)
+
+	// 有 GROUP BY 时统计分组数
+	count, err = s.userDAO.Count(ctx, AggregateEndPoint[User]{GroupBy: []string{"age"}})
+	s.Require().NoError(err)
+	s.Equal(int64(2), count)
+
+	exists, err := s.userDAO.Exists(ctx, AggregateEndPoint[User]{Conditions: map[string]any{"name": Eq("Bob")}})
+	s.Require().NoError(err)
+	s.True(exists)
+	exists, err = s.userDAO.Exists(ctx, AggregateEndPoint[User]{Conditions: map[string]any{"name": Eq("Dave")}})
+	s.Require().NoError(err)
+	s.False(exists)
+
+	groups, err := Aggregate[ageGroup](ctx, s.userDAO, AggregateEndPoint[User]{
+		Aggregates: []Aggregation{CountAll().As("users"), Avg("id")},
+		GroupBy:    []string{"age"},
+		Having:     map[string]any{"users": Gte(1)},
+		SortKeys:   []SortKey{{Column: "users", Desc: true}},
+	})
+	s.Require().NoError(err)
+	s.Equal([]ageGroup{{Age: 30, Users: 2, Avg: 2}, {Age: 40, Users: 1, Avg: 2}}, groups)
+
+	groups, err = Aggregate[ageGroup](ctx, s.userDAO, AggregateEndPoint[User]{
+		Aggregates: []Aggregation{CountAll().As("users"), Avg("id")},
+		GroupBy:    []string{"age"},
+		Having:     map[string]any{"users": Gt(1)},
+	})
+	s.Require().NoError(err)
+	s.Equal([]ageGroup{{Age: 30, Users: 2, Avg: 2}}, groups)
+
+	// 单列结果可以直接扫描为标量
+	sums, err := Aggregate[int64](ctx, s.userDAO, AggregateEndPoint[User]{Aggregates: []Aggregation{Sum("age")}})
+	s.Require().NoError(err)
+	s.Equal([]int64{100}, sums)
+
+	exists, err = s.userDAO.Query().Where(map[string]any{"age": Gte(40)}).Exists(ctx)
+	s.Require().NoError(err)
+	s.True(exists)
+}
+
+func TestAggregateSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[post](newSQLiteDB(t, postsSchema...))
+
+	_, err := dao.Delete(ctx, DeleteEndPoint[post]{Table: "posts", Conditions: map[string]any{"id": Eq(1)}})
+	require.NoError(t, err)
+
+	count, err := dao.Count(ctx, AggregateEndPoint[post]{Table: "posts"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	exists, err := dao.Exists(ctx, AggregateEndPoint[post]{Table: "posts", Conditions: map[string]any{"id": Eq(1)}})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	count, err = dao.WithTrashed().Count(ctx, AggregateEndPoint[post]{Table: "posts"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
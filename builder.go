@@ -11,24 +11,28 @@ import (
 )
 
 // buildTableClause 构建 FROM 子句
-func buildTableClause(table string) (string, error) {
+func buildTableClause(dialect Dialect, table string) (string, error) {
 	if table == "" {
 		return "", errors.New("empty table")
 	}
-	return table, nil
+	return quoteTable(dialect, table), nil
 }
 
-// buildFieldsClause 构建 SELECT 的字段部分
-func buildFieldsClause(fields []string) string {
+// buildFieldsClause 构建 SELECT 的字段部分，列名会被引用，表达式原样保留
+func buildFieldsClause(dialect Dialect, fields []string) string {
 	if len(fields) == 0 || (len(fields) == 1 && fields[0] == "*") {
 		return "*"
 	}
-	return strings.Join(fields, ",")
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		quoted[i] = quoteIdent(dialect, f)
+	}
+	return strings.Join(quoted, ",")
 }
 
-// buildWhereClause 从 conditions map 构建 WHERE 子句
-func buildWhereClause(conditions map[string]any) (string, []any, error) {
-	query, args, err := buildConditions(conditions)
+// buildWhereClause 从条件树构建 WHERE 子句
+func buildWhereClause(dialect Dialect, conditions Condition) (string, []any, error) {
+	query, args, err := buildCondition(dialect, conditions)
 	if err != nil {
 		return "", nil, err
 	}
@@ -38,7 +42,59 @@ func buildWhereClause(conditions map[string]any) (string, []any, error) {
 	return fmt.Sprintf("WHERE %v", query), args, nil
 }
 
-func buildConditions(conditions map[string]any) (string, []any, error) {
+// buildCondition 递归构建条件树
+func buildCondition(dialect Dialect, condition Condition) (string, []any, error) {
+	return buildConditionWith(dialect, condition, nil)
+}
+
+// buildConditionWith 递归构建条件树。exprs 将 Operator 条件的键映射为 SQL 表达式
+// (如 HAVING 中的聚合别名 total -> SUM(amount))，为 nil 时键按列名处理
+func buildConditionWith(dialect Dialect, condition Condition, exprs map[string]string) (string, []any, error) {
+	switch c := condition.(type) {
+	case nil:
+		return "", nil, nil
+	case map[string]any:
+		return buildConditionsWith(dialect, c, exprs)
+	case And:
+		return buildJunction(dialect, c, " AND ", exprs)
+	case AnyOf:
+		return buildJunction(dialect, c, " OR ", exprs)
+	case Or:
+		return buildJunction(dialect, c.conditions(), " OR ", exprs)
+	case Not:
+		query, args, err := buildConditionWith(dialect, c.Condition, exprs)
+		if err != nil || query == "" {
+			return "", nil, err
+		}
+		return fmt.Sprintf("NOT (%s)", query), args, nil
+	}
+	return "", nil, fmt.Errorf("unsupported condition type %T", condition)
+}
+
+// buildJunction 以 sep 连接子条件，空的子条件会被忽略
+func buildJunction(dialect Dialect, conditions []Condition, sep string, exprs map[string]string) (string, []any, error) {
+	var (
+		parts []string
+		args  []any
+	)
+	for _, sub := range conditions {
+		subQuery, subArgs, err := buildConditionWith(dialect, sub, exprs)
+		if err != nil {
+			return "", nil, err
+		}
+		if subQuery != "" {
+			parts = append(parts, fmt.Sprintf("(%s)", subQuery))
+			args = append(args, subArgs...)
+		}
+	}
+	return strings.Join(parts, sep), args, nil
+}
+
+func buildConditions(dialect Dialect, conditions map[string]any) (string, []any, error) {
+	return buildConditionsWith(dialect, conditions, nil)
+}
+
+func buildConditionsWith(dialect Dialect, conditions map[string]any, exprs map[string]string) (string, []any, error) {
 	if len(conditions) == 0 {
 		return "", nil, nil
 	}
@@ -49,21 +105,36 @@ func buildConditions(conditions map[string]any) (string, []any, error) {
 	)
 	for _, k := range sortedKeys(conditions) {
 		v := conditions[k]
-		if orConds, ok := v.(Or); ok {
-			var orParts []string
-			for _, subCond := range orConds {
-				subQuery, subArgs, err := buildConditions(subCond)
-				if err != nil {
-					return "", nil, err
-				}
-				if subQuery != "" {
-					orParts = append(orParts, fmt.Sprintf("(%s)", subQuery))
-					args = append(args, subArgs...)
-				}
+		// 条件节点作为值时忽略其键
+		switch v.(type) {
+		case And, AnyOf, Or, Not:
+			subQuery, subArgs, err := buildConditionWith(dialect, v, exprs)
+			if err != nil {
+				return "", nil, err
+			}
+			if subQuery != "" {
+				prepareConditions = append(prepareConditions, fmt.Sprintf("(%s)", subQuery))
+				args = append(args, subArgs...)
 			}
-			if len(orParts) > 0 {
-				prepareConditions = append(prepareConditions, fmt.Sprintf("(%s)", strings.Join(orParts, " OR ")))
+			continue
+		}
+
+		if op, ok := v.(Operator); ok {
+			var (
+				opQuery string
+				opArgs  []any
+				err     error
+			)
+			if expr, ok := exprs[k]; ok {
+				opQuery, opArgs, err = op.render(dialect, expr)
+			} else {
+				opQuery, opArgs, err = op.build(dialect, k)
 			}
+			if err != nil {
+				return "", nil, err
+			}
+			prepareConditions = append(prepareConditions, opQuery)
+			args = append(args, opArgs...)
 			continue
 		}
 
@@ -85,7 +156,7 @@ func buildConditions(conditions map[string]any) (string, []any, error) {
 				return "", nil, err
 			}
 
-			k = fmt.Sprintf("(%v IN %v)", k, inQuery)
+			k = fmt.Sprintf("(%v IN %v)", quoteIdent(dialect, k), inQuery)
 			args = append(args, inArgs...)
 		} else {
 			k = fmt.Sprintf("(%v?)", k)
@@ -104,8 +175,24 @@ func buildAppendsClause(appends []string) string {
 	return ""
 }
 
+// buildOrderByClause 构建 ORDER BY 子句，列名会被校验并引用
+func buildOrderByClause(dialect Dialect, keys []SortKey) (string, error) {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		if err := validateIdentifier(k.Column); err != nil {
+			return "", err
+		}
+		order := "ASC"
+		if k.Desc {
+			order = "DESC"
+		}
+		parts[i] = fmt.Sprintf("%s %s", dialect.Quote(k.Column), order)
+	}
+	return "ORDER BY " + strings.Join(parts, ", "), nil
+}
+
 // buildSetClauseForUpdate 构建 UPDATE 的 SET 子句
-func buildSetClauseForUpdate(rows map[string]any) (string, []any, error) {
+func buildSetClauseForUpdate(dialect Dialect, rows map[string]any) (string, []any, error) {
 	if len(rows) == 0 {
 		return "", nil, errors.New("empty rows for update")
 	}
@@ -115,12 +202,28 @@ func buildSetClauseForUpdate(rows map[string]any) (string, []any, error) {
 	)
 	for _, k := range sortedKeys(rows) {
 		v := rows[k]
-		prepareRows = append(prepareRows, fmt.Sprintf("%v = ?", k))
+		column := quoteIdent(dialect, k)
+		if expr, ok := v.(rawExpr); ok {
+			prepareRows = append(prepareRows, fmt.Sprintf("%v = %v", column, expr))
+			continue
+		}
+		prepareRows = append(prepareRows, fmt.Sprintf("%v = ?", column))
 		args = append(args, v)
 	}
 	return strings.Join(prepareRows, ","), args, nil
 }
 
+// rawExpr 原样写入 SET 子句的 SQL 表达式 (如 version = version + 1)，仅供内部生成
+type rawExpr string
+
+// buildReturningClause 构建 RETURNING 子句
+func buildReturningClause(dialect Dialect, columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("RETURNING %v", strings.Join(quoteIdents(dialect, columns), ","))
+}
+
 func sortedKeys(m map[string]any) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -11,13 +11,13 @@ import (
 
 func TestBuildTableClause(t *testing.T) {
 	t.Run("valid table", func(t *testing.T) {
-		result, err := buildTableClause("users")
+		result, err := buildTableClause(SQLite, "users")
 		assert.NoError(t, err)
-		assert.Equal(t, "users", result)
+		assert.Equal(t, "`users`", result)
 	})
 
 	t.Run("empty table", func(t *testing.T) {
-		_, err := buildTableClause("")
+		_, err := buildTableClause(SQLite, "")
 		assert.Error(t, err)
 		assert.Equal(t, "empty table", err.Error())
 	})
@@ -25,29 +25,29 @@ func TestBuildTableClause(t *testing.T) {
 
 func TestBuildFieldsClause(t *testing.T) {
 	t.Run("empty fields returns *", func(t *testing.T) {
-		assert.Equal(t, "*", buildFieldsClause(nil))
-		assert.Equal(t, "*", buildFieldsClause([]string{}))
+		assert.Equal(t, "*", buildFieldsClause(SQLite, nil))
+		assert.Equal(t, "*", buildFieldsClause(SQLite, []string{}))
 	})
 
 	t.Run("single wildcard returns *", func(t *testing.T) {
-		assert.Equal(t, "*", buildFieldsClause([]string{"*"}))
+		assert.Equal(t, "*", buildFieldsClause(SQLite, []string{"*"}))
 	})
 
 	t.Run("specific fields", func(t *testing.T) {
-		assert.Equal(t, "id,name,age", buildFieldsClause([]string{"id", "name", "age"}))
+		assert.Equal(t, "`id`,`name`,`age`", buildFieldsClause(SQLite, []string{"id", "name", "age"}))
 	})
 }
 
 func TestBuildWhereClause(t *testing.T) {
 	t.Run("empty conditions", func(t *testing.T) {
-		query, args, err := buildWhereClause(nil)
+		query, args, err := buildWhereClause(SQLite, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, "", query)
 		assert.Nil(t, args)
 	})
 
 	t.Run("single condition", func(t *testing.T) {
-		query, args, err := buildWhereClause(map[string]any{"id = ": 1})
+		query, args, err := buildWhereClause(SQLite, map[string]any{"id = ": 1})
 		assert.NoError(t, err)
 		assert.Equal(t, "WHERE (id = ?)", query)
 		assert.Equal(t, []any{1}, args)
@@ -56,29 +56,29 @@ func TestBuildWhereClause(t *testing.T) {
 
 func TestBuildConditions_InClause(t *testing.T) {
 	t.Run("IN with slice generates correct SQL", func(t *testing.T) {
-		query, args, err := buildConditions(map[string]any{
+		query, args, err := buildConditions(SQLite, map[string]any{
 			"id": []int{1, 2, 3},
 		})
 		require.NoError(t, err)
-		// Should produce: (id IN (?, ?, ?))
-		assert.Contains(t, query, "id IN")
+		// Should produce: (`id` IN (?, ?, ?))
+		assert.Contains(t, query, "`id` IN")
 		assert.Contains(t, query, "?, ?, ?")
 		assert.NotContains(t, query, "IN IN") // The old bug was generating double IN
 		assert.Equal(t, []any{1, 2, 3}, args)
 	})
 
 	t.Run("IN with string slice", func(t *testing.T) {
-		query, args, err := buildConditions(map[string]any{
+		query, args, err := buildConditions(SQLite, map[string]any{
 			"name": []string{"Alice", "Bob"},
 		})
 		require.NoError(t, err)
-		assert.Contains(t, query, "name IN")
+		assert.Contains(t, query, "`name` IN")
 		assert.Contains(t, query, "?, ?")
 		assert.Equal(t, []any{"Alice", "Bob"}, args)
 	})
 
 	t.Run("IN with empty slice returns error", func(t *testing.T) {
-		_, _, err := buildConditions(map[string]any{
+		_, _, err := buildConditions(SQLite, map[string]any{
 			"id": []int{},
 		})
 		assert.Error(t, err)
@@ -89,7 +89,7 @@ func TestBuildConditions_NilValue(t *testing.T) {
 	t.Run("nil value does not panic", func(t *testing.T) {
 		// This used to panic because reflect.ValueOf(nil).Kind() panics
 		assert.NotPanics(t, func() {
-			query, _, err := buildConditions(map[string]any{
+			query, _, err := buildConditions(SQLite, map[string]any{
 				"deleted_at IS ": nil,
 			})
 			assert.NoError(t, err)
@@ -100,31 +100,41 @@ func TestBuildConditions_NilValue(t *testing.T) {
 
 func TestBuildSetClauseForUpdate(t *testing.T) {
 	t.Run("empty rows", func(t *testing.T) {
-		_, _, err := buildSetClauseForUpdate(nil)
+		_, _, err := buildSetClauseForUpdate(SQLite, nil)
 		assert.Error(t, err)
 		assert.Equal(t, "empty rows for update", err.Error())
 	})
 
 	t.Run("single row", func(t *testing.T) {
-		query, args, err := buildSetClauseForUpdate(map[string]any{"name": "Alice"})
+		query, args, err := buildSetClauseForUpdate(SQLite, map[string]any{"name": "Alice"})
 		assert.NoError(t, err)
-		assert.Equal(t, "name = ?", query)
+		assert.Equal(t, "`name` = ?", query)
 		assert.Equal(t, []any{"Alice"}, args)
 	})
 
 	t.Run("stable order", func(t *testing.T) {
-		query, args, err := buildSetClauseForUpdate(map[string]any{
+		query, args, err := buildSetClauseForUpdate(SQLite, map[string]any{
 			"b": 2,
 			"a": 1,
 		})
 		assert.NoError(t, err)
-		assert.Equal(t, "a = ?,b = ?", query)
+		assert.Equal(t, "`a` = ?,`b` = ?", query)
 		assert.Equal(t, []any{1, 2}, args)
 	})
+
+	t.Run("raw expression", func(t *testing.T) {
+		query, args, err := buildSetClauseForUpdate(SQLite, map[string]any{
+			"name":    "Alice",
+			"version": rawExpr("version + 1"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "`name` = ?,`version` = version + 1", query)
+		assert.Equal(t, []any{"Alice"}, args)
+	})
 }
 
 func TestBuildConditions_StableOrder(t *testing.T) {
-	query, args, err := buildConditions(map[string]any{
+	query, args, err := buildConditions(SQLite, map[string]any{
 		"b = ": 2,
 		"a = ": 1,
 	})
@@ -142,3 +152,119 @@ func TestBuildAppendsClause(t *testing.T) {
 		assert.Equal(t, "ORDER BY id ASC LIMIT 10", buildAppendsClause([]string{"ORDER BY id ASC", "LIMIT 10"}))
 	})
 }
+
+func TestBuildReturningClause(t *testing.T) {
+	assert.Equal(t, "", buildReturningClause(SQLite, nil))
+	assert.Equal(t, "RETURNING `id`", buildReturningClause(SQLite, []string{"id"}))
+	assert.Equal(t, "RETURNING `id`,`created_at`", buildReturningClause(SQLite, []string{"id", "created_at"}))
+}
+
+func TestBuildConditions_Operators(t *testing.T) {
+	cases := []struct {
+		name  string
+		conds map[string]any
+		query string
+		args  []any
+	}{
+		{"eq", map[string]any{"age": Eq(30)}, "(`age` = ?)", []any{30}},
+		{"eq nil", map[string]any{"deleted_at": Eq(nil)}, "(`deleted_at` IS NULL)", nil},
+		{"ne", map[string]any{"age": Ne(30)}, "(`age` <> ?)", []any{30}},
+		{"ne nil", map[string]any{"deleted_at": Ne(nil)}, "(`deleted_at` IS NOT NULL)", nil},
+		{"gt", map[string]any{"age": Gt(30)}, "(`age` > ?)", []any{30}},
+		{"gte", map[string]any{"age": Gte(30)}, "(`age` >= ?)", []any{30}},
+		{"lt", map[string]any{"age": Lt(30)}, "(`age` < ?)", []any{30}},
+		{"lte", map[string]any{"age": Lte(30)}, "(`age` <= ?)", []any{30}},
+		{"like", map[string]any{"name": Like("A%")}, "(`name` LIKE ?)", []any{"A%"}},
+		{"ilike", map[string]any{"name": ILike("a%")}, "(LOWER(`name`) LIKE LOWER(?))", []any{"a%"}},
+		{"between", map[string]any{"age": Between(18, 65)}, "(`age` BETWEEN ? AND ?)", []any{18, 65}},
+		{"in variadic", map[string]any{"id": In(1, 2, 3)}, "(`id` IN (?, ?, ?))", []any{1, 2, 3}},
+		{"in slice", map[string]any{"id": In([]int64{1, 2})}, "(`id` IN (?, ?))", []any{int64(1), int64(2)}},
+		{"not in", map[string]any{"id": NotIn(1, 2)}, "(`id` NOT IN (?, ?))", []any{1, 2}},
+		{"is null", map[string]any{"deleted_at": IsNull()}, "(`deleted_at` IS NULL)", nil},
+		{"is not null", map[string]any{"deleted_at": IsNotNull()}, "(`deleted_at` IS NOT NULL)", nil},
+		{"qualified column", map[string]any{"u.age": Gt(1)}, "(`u`.`age` > ?)", []any{1}},
+		{"mixed with legacy keys", map[string]any{"age": Gt(18), "name = ": "Alice"}, "(`age` > ?) AND (name = ?)", []any{18, "Alice"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, args, err := buildConditions(SQLite, c.conds)
+			require.NoError(t, err)
+			assert.Equal(t, c.query, query)
+			assert.Equal(t, c.args, args)
+		})
+	}
+}
+
+func TestBuildConditions_OperatorInvalidColumn(t *testing.T) {
+	for _, key := range []string{"age =", "age; DROP TABLE users", "1age", ""} {
+		_, _, err := buildConditions(SQLite, map[string]any{key: Eq(1)})
+		assert.Error(t, err, key)
+	}
+
+	_, _, err := buildConditions(SQLite, map[string]any{"id": In()})
+	assert.Error(t, err)
+	_, _, err = buildConditions(SQLite, map[string]any{"id": In([]int{})})
+	assert.Error(t, err)
+}
+
+func TestBuildCondition_Tree(t *testing.T) {
+	t.Run("nested and/or/not", func(t *testing.T) {
+		query, args, err := buildCondition(SQLite, And{
+			map[string]any{"a": Eq(1)},
+			AnyOf{
+				map[string]any{"b": Eq(2)},
+				And{
+					map[string]any{"c": Eq(3)},
+					Not{Condition: map[string]any{"d": Eq(4)}},
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "((`a` = ?)) AND (((`b` = ?)) OR (((`c` = ?)) AND (NOT ((`d` = ?)))))", query)
+		assert.Equal(t, []any{1, 2, 3, 4}, args)
+	})
+
+	t.Run("map entries stay sorted inside nodes", func(t *testing.T) {
+		query, args, err := buildCondition(SQLite, Or{map[string]any{"b": Eq(2), "a": Eq(1)}})
+		require.NoError(t, err)
+		assert.Equal(t, "((`a` = ?) AND (`b` = ?))", query)
+		assert.Equal(t, []any{1, 2}, args)
+	})
+
+	t.Run("empty children are skipped", func(t *testing.T) {
+		query, args, err := buildCondition(SQLite, And{nil, map[string]any{}, Or{}, AnyOf{}, Not{}, map[string]any{"a": Eq(1)}})
+		require.NoError(t, err)
+		assert.Equal(t, "((`a` = ?))", query)
+		assert.Equal(t, []any{1}, args)
+
+		query, _, err = buildCondition(SQLite, And{})
+		require.NoError(t, err)
+		assert.Equal(t, "", query)
+	})
+
+	t.Run("node as map value keeps legacy behaviour", func(t *testing.T) {
+		query, args, err := buildConditions(SQLite, map[string]any{
+			"name = ":  "Alice",
+			"or_group": Or{{"age = ": 30}, {"age = ": 40}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "(name = ?) AND (((age = ?)) OR ((age = ?)))", query)
+		assert.Equal(t, []any{"Alice", 30, 40}, args)
+
+		query, _, err = buildConditions(SQLite, map[string]any{
+			"any": AnyOf{map[string]any{"age": Eq(30)}, Not{Condition: map[string]any{"name": Eq("Bob")}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "(((`age` = ?)) OR (NOT ((`name` = ?))))", query)
+	})
+
+	t.Run("errors propagate from nested nodes", func(t *testing.T) {
+		_, _, err := buildCondition(SQLite, AnyOf{Not{Condition: map[string]any{"bad key": Eq(1)}}})
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, _, err := buildCondition(SQLite, "id = 1")
+		assert.EqualError(t, err, "unsupported condition type string")
+	})
+}
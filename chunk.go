@@ -0,0 +1,140 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BatchError is returned by BatchInsert, BatchInsertReturning and BatchUpsert when one chunk of a chunked insert fails.
+// Rows [Start, End) of the endpoint belong to the failed chunk.
+type BatchError struct {
+	Chunk int   // Chunk 失败的批次，从 0 开始
+	Start int   // Start 该批第一行在 Rows 中的下标
+	End   int   // End 该批最后一行的下一个下标
+	Err   error // Err 驱动返回的错误
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch insert chunk %d (rows %d-%d) failed: %v", e.Chunk, e.Start, e.End-1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error { return e.Err }
+
+// sqlServerMaxRows SQL Server 的 INSERT ... VALUES 最多 1000 行
+const sqlServerMaxRows = 1000
+
+// chunkSize 返回每批的行数：不超过方言的参数上限，ChunkSize 只能调小
+func chunkSize(dialect Dialect, columns, requested int) int {
+	size := dialect.MaxParams() / max(columns, 1)
+	if dialect.Name() == SQLServer.Name() {
+		size = min(size, sqlServerMaxRows)
+	}
+	if requested > 0 {
+		size = min(size, requested)
+	}
+	return max(size, 1)
+}
+
+// chunk 一批待执行的 INSERT
+type chunk struct {
+	start, end int
+	query      string
+	args       []any
+}
+
+// batchStatement 为一批行生成 SQL，如 BatchInsertEndpoint / BatchUpsertEndpoint 的 point2Sql
+type batchStatement func(rows []map[string]any) (string, []any, error)
+
+// batchChunks 先生成所有批次的 SQL，避免行结构错误在部分批次写入后才被发现
+func (d *DAO[T]) batchChunks(rows []map[string]any, requested int, build batchStatement) ([]chunk, error) {
+	if len(rows) == 0 {
+		_, _, err := build(rows)
+		return nil, err
+	}
+	// 每批只和本批第一行比较，这里先确保所有行的列与第一行一致
+	columns := rows[0]
+	for _, row := range rows[1:] {
+		if len(row) != len(columns) {
+			return nil, errors.New("rows transfer failed")
+		}
+		for k := range columns {
+			if _, ok := row[k]; !ok {
+				return nil, errors.New("rows transfer failed")
+			}
+		}
+	}
+	size := chunkSize(d.cfg.dialect, len(columns), requested)
+	var chunks []chunk
+	for start := 0; start < len(rows); start += size {
+		end := min(start+size, len(rows))
+		query, args, err := build(rows[start:end])
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk{start: start, end: end, query: query, args: args})
+	}
+	return chunks, nil
+}
+
+// execChunks 依次执行各批次，返回已写入的总行数；失败时返回 *BatchError
+func (d *DAO[T]) execChunks(ctx context.Context, op, table string, chunks []chunk) (int64, error) {
+	return runChunks(chunks, func(c chunk) (int64, error) {
+		return d.execContext(ctx, op, table, c.query, c.args)
+	})
+}
+
+// runChunks 依次对各批次调用 run 并累加影响的行数；只有一批时原样返回错误，否则包装为 *BatchError
+func runChunks(chunks []chunk, run func(c chunk) (int64, error)) (int64, error) {
+	var total int64
+	for i, c := range chunks {
+		affected, err := run(c)
+		total += affected
+		if err != nil {
+			if len(chunks) == 1 {
+				return total, err
+			}
+			return total, &BatchError{Chunk: i, Start: c.start, End: c.end, Err: err}
+		}
+	}
+	return total, nil
+}
+
+// execChunksAtomic 在同一事务中执行各批次，任一批失败时全部撤销；已经处于事务中时使用保存点
+func (d *DAO[T]) execChunksAtomic(ctx context.Context, table string, chunks []chunk) (int64, error) {
+	if len(chunks) == 1 {
+		// 单条语句本身是原子的
+		return d.execChunks(ctx, "BatchInsert", table, chunks)
+	}
+	var (
+		tx    *sqlx.Tx
+		level *txLevel
+		err   error
+	)
+	switch db := d.db.(type) {
+	case *sqlx.DB:
+		if tx, err = db.BeginTxx(ctx, nil); err != nil {
+			return 0, err
+		}
+		level = newTxLevel()
+	case *sqlx.Tx:
+		tx = db
+		if level, err = beginLevel(ctx, tx, d.cfg.dialect, d.txn); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("atomic batch insert: %w", ErrTxUnsupported)
+	}
+	txDAO := &DAO[T]{db: tx, cfg: d.cfg, scope: d.scope, txn: level}
+	total, err := txDAO.execChunks(ctx, "BatchInsert", table, chunks)
+	if err != nil {
+		_ = endLevel(ctx, tx, d.cfg.dialect, level, true)
+		return 0, err
+	}
+	if err := endLevel(ctx, tx, d.cfg.dialect, level, false); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,217 @@
+package db_dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- chunk_test.go: Tests for chunked BatchInsert, BatchInsertReturning and BatchUpsert ---
+
+func TestChunkSize(t *testing.T) {
+	assert.Equal(t, 21845, chunkSize(Postgres, 3, 0))
+	assert.Equal(t, 10922, chunkSize(SQLite, 3, 0))
+	assert.Equal(t, 1000, chunkSize(SQLServer, 1, 0))
+	assert.Equal(t, 700, chunkSize(SQLServer, 3, 0))
+	assert.Equal(t, 10, chunkSize(Postgres, 3, 10))
+	// ChunkSize 不能突破参数上限
+	assert.Equal(t, 700, chunkSize(SQLServer, 3, 5000))
+	assert.Equal(t, 1, chunkSize(SQLServer, 5000, 0))
+}
+
+// countingHook 记录执行的语句数
+func countingHook(n *int) Hook {
+	return HookFuncs{After: func(context.Context, *QueryEvent) { *n++ }}
+}
+
+func (s *DAOTestSuite) TestBatchInsert_Chunked() {
+	ctx := context.Background()
+	var statements int
+	dao := NewDAO[User](s.db, WithHooks(countingHook(&statements)))
+
+	rows := []map[string]any{
+		{"id": 3, "name": "C", "age": 1},
+		{"id": 4, "name": "D", "age": 2},
+		{"id": 5, "name": "E", "age": 3},
+		{"id": 6, "name": "F", "age": 4},
+		{"id": 7, "name": "G", "age": 5},
+	}
+	affected, err := dao.BatchInsert(ctx, BatchInsertEndpoint[User]{Rows: rows, ChunkSize: 2})
+	s.Require().NoError(err)
+	s.Equal(int64(5), affected)
+	s.Equal(3, statements)
+
+	// 第二批与已有数据主键冲突：第一批已写入，错误指明失败的批次与行范围
+	rows = []map[string]any{
+		{"id": 8, "name": "H", "age": 1},
+		{"id": 9, "name": "I", "age": 2},
+		{"id": 10, "name": "J", "age": 3},
+		{"id": 1, "name": "dup", "age": 4},
+	}
+	affected, err = dao.BatchInsert(ctx, BatchInsertEndpoint[User]{Rows: rows, ChunkSize: 2})
+	var batchErr *BatchError
+	s.Require().ErrorAs(err, &batchErr)
+	s.Equal(1, batchErr.Chunk)
+	s.Equal(2, batchErr.Start)
+	s.Equal(4, batchErr.End)
+	s.Contains(err.Error(), "batch insert chunk 1 (rows 2-3) failed")
+	s.Equal(int64(2), affected)
+
+	// 行结构不一致时在执行任何一批之前报错
+	statements = 0
+	_, err = dao.BatchInsert(ctx, BatchInsertEndpoint[User]{Rows: []map[string]any{
+		{"id": 20, "name": "X"},
+		{"id": 21, "age": 1},
+	}, ChunkSize: 1})
+	s.EqualError(err, "rows transfer failed")
+	s.Equal(0, statements)
+
+	// 超过 SQLite 参数上限时自动分批
+	rows = make([]map[string]any, 12000)
+	for i := range rows {
+		rows[i] = map[string]any{"id": 100 + i, "name": "bulk", "age": i}
+	}
+	statements = 0
+	affected, err = dao.BatchInsert(ctx, BatchInsertEndpoint[User]{Rows: rows})
+	s.Require().NoError(err)
+	s.Equal(int64(12000), affected)
+	s.Equal(2, statements)
+}
+
+func TestBatchInsertReturning_Chunked(t *testing.T) {
+	db := newSQLiteDB(t, createUsersTable, seedUsers)
+	var statements int
+	dao := NewDAO[User](db, WithHooks(countingHook(&statements)))
+	ctx := context.Background()
+
+	rows := make([]map[string]any, 5)
+	for i := range rows {
+		rows[i] = map[string]any{"name": "chunk", "age": i}
+	}
+	var users []User
+	affected, err := dao.BatchInsertReturning(ctx, BatchInsertEndpoint[User]{Rows: rows, ChunkSize: 2, Returning: []string{"id"}, Model: &users})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), affected)
+	assert.Equal(t, 3, statements)
+	require.Len(t, users, 5)
+	for i, u := range users {
+		assert.Equal(t, int64(3+i), u.ID, "row %d", i)
+	}
+
+	// 超过 SQLite 参数上限时自动分批 (2 列，每批 16383 行)
+	rows = make([]map[string]any, 20000)
+	for i := range rows {
+		rows[i] = map[string]any{"name": "bulk", "age": i}
+	}
+	users = nil
+	statements = 0
+	affected, err = dao.BatchInsertReturning(ctx, BatchInsertEndpoint[User]{Rows: rows, Returning: []string{"id"}, Model: &users})
+	require.NoError(t, err)
+	assert.Equal(t, int64(20000), affected)
+	assert.Equal(t, 2, statements)
+	assert.Equal(t, int64(8), users[0].ID)
+	assert.Equal(t, int64(20007), users[19999].ID)
+
+	// 第二批失败：第一批的主键已写回，错误指明失败的批次
+	rows = []map[string]any{
+		{"id": 30000, "name": "A", "age": 1},
+		{"id": 30001, "name": "B", "age": 2},
+		{"id": 1, "name": "dup", "age": 3},
+	}
+	users = nil
+	affected, err = dao.BatchInsertReturning(ctx, BatchInsertEndpoint[User]{Rows: rows, ChunkSize: 2, Returning: []string{"id"}, Model: &users})
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 1, batchErr.Chunk)
+	assert.Equal(t, int64(2), affected)
+	assert.Equal(t, int64(30001), users[1].ID)
+}
+
+func TestBatchUpsert_Chunked(t *testing.T) {
+	db := newSQLiteDB(t, createUsersTable, seedUsers)
+	var statements int
+	dao := NewDAO[User](db, WithHooks(countingHook(&statements)))
+	ctx := context.Background()
+
+	// 3 列，超过 SQLite 参数上限 (每批 10922 行) 时自动分批
+	rows := make([]map[string]any, 12000)
+	for i := range rows {
+		rows[i] = map[string]any{"id": i + 1, "name": "bulk", "age": i}
+	}
+	_, err := dao.BatchUpsert(ctx, BatchUpsertEndpoint[User]{Rows: rows, ConflictColumns: []string{"id"}, UpdateColumns: []string{"name", "age"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, statements)
+
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM users WHERE name = 'bulk'`))
+	assert.Equal(t, 12000, count)
+}
+
+func TestBatchInsert_Atomic(t *testing.T) {
+	db := newSQLiteDB(t, createUsersTable, `INSERT INTO users (id, name, age) VALUES (1, 'Alice', 30)`)
+	dao := NewDAO[User](db)
+	ctx := context.Background()
+
+	rows := []map[string]any{
+		{"id": 2, "name": "B", "age": 1},
+		{"id": 3, "name": "C", "age": 2},
+		{"id": 1, "name": "dup", "age": 3},
+	}
+	affected, err := dao.BatchInsert(ctx, BatchInsertEndpoint[User]{Rows: rows, ChunkSize: 2, Atomic: true})
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 1, batchErr.Chunk)
+	assert.Equal(t, int64(0), affected)
+
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM users`))
+	assert.Equal(t, 1, count, "the first chunk is rolled back")
+
+	rows[2]["id"] = 4
+	affected, err = dao.BatchInsert(ctx, BatchInsertEndpoint[User]{Rows: rows, ChunkSize: 2, Atomic: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), affected)
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM users`))
+	assert.Equal(t, 4, count)
+}
+
+func TestBatchInsert_AtomicInTx(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, createUsersTable, seedUsers)
+	dao := NewDAO[User](db)
+
+	err := dao.WithTx(ctx, nil, func(tx IDAO[User]) error {
+		if _, err := tx.UpdateByID(ctx, 1, map[string]any{"age": 31}); err != nil {
+			return err
+		}
+		// 第 3 批与已有的 id 2 冲突，前两批必须随保存点一起撤销，调用方之前的修改保留
+		rows := []map[string]any{
+			{"id": 3, "name": "C", "age": 1},
+			{"id": 4, "name": "D", "age": 2},
+			{"id": 2, "name": "dup", "age": 3},
+		}
+		affected, err := tx.BatchInsert(ctx, BatchInsertEndpoint[User]{Rows: rows, ChunkSize: 1, Atomic: true})
+		var batchErr *BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 2, batchErr.Chunk)
+		assert.Equal(t, int64(0), affected)
+
+		count, err := tx.Count(ctx, AggregateEndPoint[User]{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 31, userAge(t, dao, 1))
+
+	// 既不能开启也不能加入事务的执行器不会悄悄退化为非原子写入
+	wrapped := NewDAO[User](struct{ Executor }{db}, WithDialect(SQLite))
+	_, err = wrapped.BatchInsert(ctx, BatchInsertEndpoint[User]{
+		Rows:      []map[string]any{{"id": 5, "name": "E", "age": 1}, {"id": 6, "name": "F", "age": 2}},
+		ChunkSize: 1,
+		Atomic:    true,
+	})
+	assert.ErrorIs(t, err, ErrTxUnsupported)
+}
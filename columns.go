@@ -0,0 +1,235 @@
+package db_dao
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// ErrInvalidColumn is matched (via errors.Is) by every *ColumnError.
+var ErrInvalidColumn = errors.New("invalid column")
+
+// ErrAppendsNotAllowed is returned in strict mode when an endpoint has free-form Appends.
+var ErrAppendsNotAllowed = errors.New("appends are not allowed in strict mode")
+
+// ColumnError is returned when column validation is enabled and an endpoint references
+// a column that is neither a db column of T nor in the allowlist.
+type ColumnError struct {
+	Clause string // Clause 出错的位置：fields / sort / condition / rows / returning / conflict / update
+	Column string // Column 被拒绝的原始字符串
+}
+
+func (e *ColumnError) Error() string {
+	return fmt.Sprintf("invalid column %q in %s", e.Column, e.Clause)
+}
+
+func (e *ColumnError) Unwrap() error { return ErrInvalidColumn }
+
+// WithColumnValidation rejects endpoints whose Fields, sort columns, condition keys, Rows keys,
+// Returning columns or upsert ConflictColumns / UpdateColumns are not db columns of T or one of
+// allowed (e.g. columns of joined tables).
+// Qualified names such as "u.name" are checked by their last part unless listed as is.
+func WithColumnValidation(allowed ...string) Option {
+	return func(c *config) {
+		c.validateColumns = true
+		if c.allowedColumns == nil {
+			c.allowedColumns = make(map[string]bool)
+		}
+		for _, column := range allowed {
+			c.allowedColumns[column] = true
+		}
+	}
+}
+
+// WithStrictMode enables column validation and additionally rejects endpoints with Appends,
+// the only part of an endpoint that is written into the SQL verbatim.
+// Queries built with DAO.Query are still allowed, since their ORDER BY / LIMIT are generated.
+func WithStrictMode() Option {
+	return func(c *config) {
+		WithColumnValidation()(c)
+		c.strict = true
+	}
+}
+
+// legacyKeyPattern 匹配旧式条件键 "列名 运算符"，如 "age >= "、"name LIKE"、"deleted_at IS"
+var legacyKeyPattern = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)\s*(?i:=|!=|<>|<=|>=|<|>|LIKE|NOT\s+LIKE|IS|IS\s+NOT)?\s*$`)
+
+// compiled 记录 QueryBuilder 生成的 Appends 及其排序列，严格模式据此放行
+type compiled struct {
+	appends  []string
+	sortKeys []SortKey
+}
+
+// columnRefs endpoint 中需要校验的部分
+type columnRefs struct {
+	fields     []string
+	sortField  string
+	sortKeys   []SortKey
+	conditions Condition
+	joins      []Join
+	having     Condition
+	aliases    []string
+	rows       []map[string]any
+	returning  []string
+	conflict   []string
+	update     []string
+	appends    []string
+	compiled   *compiled
+}
+
+// allowlist 允许的列名，nil 表示未启用校验
+type allowlist map[string]bool
+
+// allowlist 返回 T 的列与 WithColumnValidation 额外允许的列
+func (d *DAO[T]) allowlist() allowlist {
+	if !d.cfg.validateColumns {
+		return nil
+	}
+	columns := d.meta().columns
+	allowed := make(allowlist, len(columns)+len(d.cfg.allowedColumns))
+	for _, c := range columns {
+		allowed[c] = true
+	}
+	for c := range d.cfg.allowedColumns {
+		allowed[c] = true
+	}
+	return allowed
+}
+
+// checkColumns 在启用校验时检查 refs 引用的列，严格模式下拒绝非生成的 Appends
+func (d *DAO[T]) checkColumns(refs columnRefs) error {
+	allowed := d.allowlist()
+	if allowed == nil {
+		return nil
+	}
+	if d.cfg.strict && len(refs.appends) > 0 && (refs.compiled == nil || !slices.Equal(refs.appends, refs.compiled.appends)) {
+		return ErrAppendsNotAllowed
+	}
+	for _, f := range refs.fields {
+		if f != "*" {
+			if err := allowed.check("fields", f); err != nil {
+				return err
+			}
+		}
+	}
+	if len(refs.aliases) > 0 {
+		// 聚合列的别名可用于 HAVING 与排序
+		withAliases := maps.Clone(allowed)
+		for _, alias := range refs.aliases {
+			withAliases[alias] = true
+		}
+		allowed = withAliases
+	}
+	if refs.sortField != "" {
+		if err := allowed.check("sort", refs.sortField); err != nil {
+			return err
+		}
+	}
+	sortKeys := refs.sortKeys
+	if refs.compiled != nil {
+		sortKeys = append(slices.Clip(sortKeys), refs.compiled.sortKeys...)
+	}
+	for _, k := range sortKeys {
+		if err := allowed.check("sort", k.Column); err != nil {
+			return err
+		}
+	}
+	if err := allowed.checkCondition(refs.conditions); err != nil {
+		return err
+	}
+	for _, j := range refs.joins {
+		if err := allowed.checkCondition(j.On); err != nil {
+			return err
+		}
+	}
+	if err := allowed.checkCondition(refs.having); err != nil {
+		return err
+	}
+	for _, row := range refs.rows {
+		for _, k := range sortedKeys(row) {
+			if err := allowed.check("rows", k); err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range refs.returning {
+		if err := allowed.check("returning", r); err != nil {
+			return err
+		}
+	}
+	for _, c := range refs.conflict {
+		if err := allowed.check("conflict", c); err != nil {
+			return err
+		}
+	}
+	for _, c := range refs.update {
+		if err := allowed.check("update", c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check 校验单个列名：必须是合法标识符，且本身或去掉前缀后在允许列表中
+func (a allowlist) check(clause, column string) error {
+	if validateIdentifier(column) != nil || !(a[column] || a[keyColumn(column)]) {
+		return &ColumnError{Clause: clause, Column: column}
+	}
+	return nil
+}
+
+// checkCondition 递归校验条件树中的键
+func (a allowlist) checkCondition(condition Condition) error {
+	switch c := condition.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(c) {
+			switch v := c[k].(type) {
+			case And, AnyOf, Or, Not:
+				// 条件节点作为值时忽略其键
+				if err := a.checkCondition(v); err != nil {
+					return err
+				}
+				continue
+			case Operator:
+				if err := a.check("condition", k); err != nil {
+					return err
+				}
+				if len(v.args) == 1 {
+					if ref, ok := v.args[0].(columnRef); ok {
+						if err := a.check("condition", string(ref)); err != nil {
+							return err
+						}
+					}
+				}
+				continue
+			}
+			m := legacyKeyPattern.FindStringSubmatch(k)
+			if m == nil {
+				return &ColumnError{Clause: "condition", Column: k}
+			}
+			if err := a.check("condition", m[1]); err != nil {
+				return err
+			}
+		}
+	case And:
+		return a.checkJunction(c)
+	case AnyOf:
+		return a.checkJunction(c)
+	case Or:
+		return a.checkJunction(c.conditions())
+	case Not:
+		return a.checkCondition(c.Condition)
+	}
+	return nil
+}
+
+func (a allowlist) checkJunction(conditions []Condition) error {
+	for _, sub := range conditions {
+		if err := a.checkCondition(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- columns_test.go: Tests for column validation and strict mode ---
+
+func TestCheckColumns(t *testing.T) {
+	dao := NewDAO[User](nil, WithColumnValidation("o.total"))
+
+	valid := []columnRefs{
+		{fields: []string{"id", "users.name", "*"}},
+		{sortField: "age", sortKeys: []SortKey{{Column: "u.id", Desc: true}}},
+		{conditions: map[string]any{"id = ": 1, "age>=": 2, "name LIKE": "A%", "name is not": nil, "age": Gt(1)}},
+		{conditions: And{Or{{"o.total": Gt(1)}}, Not{Condition: map[string]any{"x": Or{{"id": Eq(1)}}}}}},
+		{conditions: AnyOf{Not{Condition: map[string]any{"age": Gt(1)}}, map[string]any{"id": Eq(1)}}},
+		{rows: []map[string]any{{"name": "A", "age": 1}}, returning: []string{"id"}},
+		{appends: []string{"GROUP BY age"}},
+	}
+	for _, refs := range valid {
+		assert.NoError(t, dao.checkColumns(refs), "%+v", refs)
+	}
+
+	invalid := []struct {
+		refs   columnRefs
+		clause string
+		column string
+	}{
+		{columnRefs{fields: []string{"password"}}, "fields", "password"},
+		{columnRefs{fields: []string{"count(*)"}}, "fields", "count(*)"},
+		{columnRefs{sortField: "id; DROP TABLE users"}, "sort", "id; DROP TABLE users"},
+		{columnRefs{sortKeys: []SortKey{{Column: "secret"}}}, "sort", "secret"},
+		{columnRefs{conditions: map[string]any{"1=1 OR id =": 1}}, "condition", "1=1 OR id ="},
+		{columnRefs{conditions: Or{map[string]any{"email": Eq("x")}}}, "condition", "email"},
+		{columnRefs{conditions: AnyOf{And{map[string]any{"email": Eq("x")}}}}, "condition", "email"},
+		{columnRefs{conditions: map[string]any{"total": Gt(1)}}, "condition", "total"},
+		{columnRefs{rows: []map[string]any{{"name": "A"}, {"role": "admin"}}}, "rows", "role"},
+		{columnRefs{returning: []string{"token"}}, "returning", "token"},
+		{columnRefs{conflict: []string{"email"}}, "conflict", "email"},
+		{columnRefs{update: []string{"name", "is_admin"}}, "update", "is_admin"},
+	}
+	for _, tc := range invalid {
+		err := dao.checkColumns(tc.refs)
+		var columnErr *ColumnError
+		require.ErrorAs(t, err, &columnErr, "%+v", tc.refs)
+		assert.Equal(t, tc.clause, columnErr.Clause)
+		assert.Equal(t, tc.column, columnErr.Column)
+		assert.ErrorIs(t, err, ErrInvalidColumn)
+	}
+
+	// 未启用校验时不做任何检查
+	assert.NoError(t, NewDAO[User](nil).checkColumns(columnRefs{sortField: "id; DROP TABLE users"}))
+}
+
+func TestCheckColumns_Strict(t *testing.T) {
+	dao := NewDAO[User](nil, WithDialect(SQLite), WithStrictMode())
+
+	assert.ErrorIs(t, dao.checkColumns(columnRefs{appends: []string{"LIMIT 1"}}), ErrAppendsNotAllowed)
+	assert.ErrorIs(t, dao.checkColumns(columnRefs{fields: []string{"nope"}}), ErrInvalidColumn)
+
+	// QueryBuilder 生成的 Appends 可以执行，但其排序列同样受校验
+	var users []User
+	endpoint, err := dao.Query().OrderBy("age").Limit(1).ToSelect(&users)
+	require.NoError(t, err)
+	assert.NoError(t, dao.checkColumns(columnRefs{appends: endpoint.Appends, compiled: endpoint.compiled}))
+
+	endpoint.Appends = append(endpoint.Appends, "UNION SELECT 1")
+	assert.ErrorIs(t, dao.checkColumns(columnRefs{appends: endpoint.Appends, compiled: endpoint.compiled}), ErrAppendsNotAllowed)
+
+	endpoint, err = dao.Query().OrderBy("secret").ToSelect(&users)
+	require.NoError(t, err)
+	err = dao.checkColumns(columnRefs{appends: endpoint.Appends, compiled: endpoint.compiled})
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+}
+
+func (s *DAOTestSuite) TestColumnValidation() {
+	ctx := context.Background()
+	dao := NewDAO[User](s.db, WithStrictMode())
+
+	var users []User
+	_, err := dao.Paginate(ctx, PageEndPoint[User]{Model: &users, SortField: "age desc", PageNo: 1, PageSize: 10})
+	s.ErrorIs(err, ErrInvalidColumn)
+
+	total, err := dao.Paginate(ctx, PageEndPoint[User]{Model: &users, SortField: "age", SortOrder: "DESC", PageNo: 1, PageSize: 10})
+	s.Require().NoError(err)
+	s.Equal(int64(2), total)
+	s.Equal("Bob", users[0].Name)
+
+	err = dao.Select(ctx, SelectEndPoint[User]{Model: &users, Appends: []string{"ORDER BY age"}})
+	s.ErrorIs(err, ErrAppendsNotAllowed)
+
+	err = dao.Query().Where(map[string]any{"age": Gte(30)}).OrderByDesc("age").Limit(1).Select(ctx, &users)
+	s.Require().NoError(err)
+	s.Require().Len(users, 1)
+	s.Equal("Bob", users[0].Name)
+
+	_, err = dao.Update(ctx, UpdateEndPoint[User]{Rows: map[string]any{"is_admin": true}, Conditions: map[string]any{"id": Eq(1)}})
+	var columnErr *ColumnError
+	s.Require().True(errors.As(err, &columnErr))
+	s.Equal("rows", columnErr.Clause)
+
+	_, err = dao.Delete(ctx, DeleteEndPoint[User]{Conditions: map[string]any{"1 = 1 OR id =": 1}})
+	s.ErrorIs(err, ErrInvalidColumn)
+
+	// 冲突列与更新列同样受校验
+	_, err = dao.Upsert(ctx, UpsertEndpoint[User]{Rows: map[string]any{"id": 1, "name": "A"}, ConflictColumns: []string{"id) DO UPDATE SET is_admin = 1 --"}})
+	s.ErrorIs(err, ErrInvalidColumn)
+	_, err = dao.BatchUpsert(ctx, BatchUpsertEndpoint[User]{Rows: []map[string]any{{"id": 1, "name": "A"}}, ConflictColumns: []string{"id"}, UpdateColumns: []string{"is_admin"}})
+	s.Require().True(errors.As(err, &columnErr))
+	s.Equal("update", columnErr.Clause)
+}
@@ -0,0 +1,144 @@
+package db_dao
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Operator 类型化的条件运算符。作为 Conditions 的值使用，此时键必须是合法的列名：
+//
+//	Conditions: map[string]any{
+//		"age":        db_dao.Gte(18),
+//		"name":       db_dao.Like("A%"),
+//		"deleted_at": db_dao.IsNull(),
+//	}
+type Operator struct {
+	op   string
+	args []any
+}
+
+// Eq column = value (value 为 nil 时生成 IS NULL)
+func Eq(value any) Operator { return Operator{op: "=", args: []any{value}} }
+
+// Ne column <> value (value 为 nil 时生成 IS NOT NULL)
+func Ne(value any) Operator { return Operator{op: "<>", args: []any{value}} }
+
+// Gt column > value
+func Gt(value any) Operator { return Operator{op: ">", args: []any{value}} }
+
+// Gte column >= value
+func Gte(value any) Operator { return Operator{op: ">=", args: []any{value}} }
+
+// Lt column < value
+func Lt(value any) Operator { return Operator{op: "<", args: []any{value}} }
+
+// Lte column <= value
+func Lte(value any) Operator { return Operator{op: "<=", args: []any{value}} }
+
+// Like column LIKE pattern
+func Like(pattern any) Operator { return Operator{op: "LIKE", args: []any{pattern}} }
+
+// ILike 不区分大小写的 LIKE，为兼容所有方言生成 LOWER(column) LIKE LOWER(pattern)
+func ILike(pattern any) Operator { return Operator{op: "ILIKE", args: []any{pattern}} }
+
+// Between column BETWEEN low AND high
+func Between(low, high any) Operator { return Operator{op: "BETWEEN", args: []any{low, high}} }
+
+// In column IN (values...)，也可以直接传入一个切片
+func In(values ...any) Operator { return Operator{op: "IN", args: values} }
+
+// NotIn column NOT IN (values...)，也可以直接传入一个切片
+func NotIn(values ...any) Operator { return Operator{op: "NOT IN", args: values} }
+
+// EqCol column = other，比较两列 (如 JOIN 的 ON 条件)，other 必须是合法的列名
+func EqCol(other string) Operator { return Operator{op: "=", args: []any{columnRef(other)}} }
+
+// columnRef 作为参数时按列名原样写入 SQL，仅由 EqCol 生成
+type columnRef string
+
+// IsNull column IS NULL
+func IsNull() Operator { return Operator{op: "IS NULL"} }
+
+// IsNotNull column IS NOT NULL
+func IsNotNull() Operator { return Operator{op: "IS NOT NULL"} }
+
+// identifierPattern 匹配列名，允许带表名/别名前缀 (如 u.name)
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// validateIdentifier 校验列名，防止通过键注入 SQL
+func validateIdentifier(column string) error {
+	if !identifierPattern.MatchString(column) {
+		return fmt.Errorf("invalid column name %q", column)
+	}
+	return nil
+}
+
+// build 为 column 生成条件语句，列名会被校验并引用
+func (o Operator) build(dialect Dialect, column string) (string, []any, error) {
+	if err := validateIdentifier(column); err != nil {
+		return "", nil, err
+	}
+	return o.render(dialect, dialect.Quote(column))
+}
+
+// render 为已引用的列名或内部生成的表达式生成条件语句
+func (o Operator) render(dialect Dialect, column string) (string, []any, error) {
+	if len(o.args) == 1 {
+		if ref, ok := o.args[0].(columnRef); ok {
+			if err := validateIdentifier(string(ref)); err != nil {
+				return "", nil, err
+			}
+			return fmt.Sprintf("(%v %v %v)", column, o.op, dialect.Quote(string(ref))), nil, nil
+		}
+	}
+	switch o.op {
+	case "IS NULL", "IS NOT NULL":
+		return fmt.Sprintf("(%v %v)", column, o.op), nil, nil
+	case "BETWEEN":
+		return fmt.Sprintf("(%v BETWEEN ? AND ?)", column), o.args, nil
+	case "IN", "NOT IN":
+		values := o.args
+		if len(values) == 1 && isListValue(values[0]) {
+			return buildInCondition(column, o.op, values[0])
+		}
+		if len(values) == 0 {
+			return "", nil, errors.New("empty slice passed to 'in' query")
+		}
+		return buildInCondition(column, o.op, values)
+	case "ILIKE":
+		return fmt.Sprintf("(LOWER(%v) LIKE LOWER(?))", column), o.args, nil
+	case "=", "<>":
+		if o.args[0] == nil {
+			if o.op == "=" {
+				return fmt.Sprintf("(%v IS NULL)", column), nil, nil
+			}
+			return fmt.Sprintf("(%v IS NOT NULL)", column), nil, nil
+		}
+	}
+	return fmt.Sprintf("(%v %v ?)", column, o.op), o.args, nil
+}
+
+func buildInCondition(column, op string, values any) (string, []any, error) {
+	inQuery, inArgs, err := sqlx.In("(?)", values)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("(%v %v %v)", column, op, inQuery), inArgs, nil
+}
+
+// isListValue 判断值是否应展开为 IN 列表。字节切片 (包括 json.RawMessage 等命名类型) 与 driver.Valuer 作为单个值
+func isListValue(v any) bool {
+	if v == nil {
+		return false
+	}
+	if _, ok := v.(driver.Valuer); ok {
+		return false
+	}
+	t := reflect.TypeOf(v)
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
@@ -0,0 +1,286 @@
+package db_dao
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// ErrInvalidCursor is returned when a cursor token is malformed, was tampered with,
+// or was issued for a different table, sort order or set of conditions.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// defaultCursorSecret 未配置 WithCursorSecret 时使用的进程级随机密钥，进程重启后旧游标失效。
+var defaultCursorSecret = func() []byte {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}()
+
+const (
+	cursorNext = "n"
+	cursorPrev = "p"
+)
+
+// cursorPayload 游标中编码的内容
+type cursorPayload struct {
+	Direction string            `json:"d"`
+	Values    []json.RawMessage `json:"v"`
+}
+
+// fingerprint 将游标绑定到表、连接、排序键与查询条件，防止在不同查询之间混用。
+// 条件以生成的 WHERE 子句及其参数的哈希表示，参数按 primaryKeyString 的规则规范化
+func (s CursorPageEndPoint[T]) fingerprint(dialect Dialect) (string, error) {
+	where, args, err := buildWhereClause(dialect, s.Conditions)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString(s.Table)
+	for _, j := range s.Joins {
+		b.WriteString("|")
+		b.WriteString(string(j.Type))
+		b.WriteString(" ")
+		b.WriteString(j.Table)
+	}
+	for _, k := range s.SortKeys {
+		b.WriteString("|")
+		b.WriteString(k.Column)
+		if k.Desc {
+			b.WriteString(" DESC")
+		}
+	}
+	h := sha256.New()
+	h.Write([]byte(where))
+	h.Write([]byte{0})
+	h.Write([]byte(primaryKeyString(args, false)))
+	b.WriteString("|")
+	b.WriteString(hex.EncodeToString(h.Sum(nil)))
+	return b.String(), nil
+}
+
+func signCursor(secret []byte, fingerprint string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(fingerprint))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// encodeCursor 生成 base64(payload).base64(hmac) 形式的游标
+func encodeCursor(secret []byte, fingerprint, direction string, values []any) (string, error) {
+	p := cursorPayload{Direction: direction}
+	for _, v := range values {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		p.Values = append(p.Values, raw)
+	}
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(signCursor(secret, fingerprint, payload)), nil
+}
+
+// decodeCursor 校验签名并解析游标
+func decodeCursor(secret []byte, fingerprint, token string) (cursorPayload, error) {
+	var p cursorPayload
+	payloadPart, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return p, ErrInvalidCursor
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(payloadPart)
+	if err != nil {
+		return p, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil {
+		return p, ErrInvalidCursor
+	}
+	if !hmac.Equal(sig, signCursor(secret, fingerprint, payload)) {
+		return p, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return p, ErrInvalidCursor
+	}
+	if p.Direction != cursorNext && p.Direction != cursorPrev {
+		return p, ErrInvalidCursor
+	}
+	return p, nil
+}
+
+// sortKeyFields 找到每个排序键在 T 中对应的字段
+func (s CursorPageEndPoint[T]) sortKeyFields(m *reflectx.Mapper) ([]*reflectx.FieldInfo, error) {
+	if len(s.SortKeys) == 0 {
+		return nil, errors.New("empty sort keys")
+	}
+	tm := m.TypeMap(reflect.TypeOf((*T)(nil)).Elem())
+	fields := make([]*reflectx.FieldInfo, 0, len(s.SortKeys))
+	for _, k := range s.SortKeys {
+		column := k.Column
+		if i := strings.LastIndex(column, "."); i >= 0 {
+			column = column[i+1:]
+		}
+		fi := tm.GetByPath(column)
+		if fi == nil {
+			return nil, fmt.Errorf("sort key %s not found in model", k.Column)
+		}
+		fields = append(fields, fi)
+	}
+	return fields, nil
+}
+
+// decodeValues 按字段类型解析游标中的键值
+func decodeValues(fields []*reflectx.FieldInfo, raws []json.RawMessage) ([]any, error) {
+	if len(raws) != len(fields) {
+		return nil, ErrInvalidCursor
+	}
+	values := make([]any, len(fields))
+	for i, fi := range fields {
+		v := reflect.New(fi.Field.Type)
+		if err := json.Unmarshal(raws[i], v.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = v.Elem().Interface()
+	}
+	return values, nil
+}
+
+// rowValues 读取一行中排序键的值
+func rowValues[T any](fields []*reflectx.FieldInfo, row *T) []any {
+	v := reflect.ValueOf(row).Elem()
+	values := make([]any, len(fields))
+	for i, fi := range fields {
+		values[i] = fieldValue(v, fi.Index).Interface()
+	}
+	return values
+}
+
+// buildKeysetClause 构建 keyset 条件。backward 表示向前翻页 (比较方向取反)。
+// 所有键方向一致且方言支持时使用 (k1, k2) > (?, ?)，否则展开为
+// (k1 > ?) OR (k1 = ? AND k2 > ?) 的形式。
+func buildKeysetClause(dialect Dialect, keys []SortKey, values []any, backward bool) (string, []any) {
+	greater := func(k SortKey) bool { return k.Desc == backward }
+
+	sameDirection := true
+	for _, k := range keys[1:] {
+		if k.Desc != keys[0].Desc {
+			sameDirection = false
+			break
+		}
+	}
+
+	if sameDirection && len(keys) > 1 && dialect.SupportsRowValues() {
+		columns := make([]string, len(keys))
+		marks := make([]string, len(keys))
+		for i, k := range keys {
+			columns[i] = dialect.Quote(k.Column)
+			marks[i] = "?"
+		}
+		op := "<"
+		if greater(keys[0]) {
+			op = ">"
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), op, strings.Join(marks, ", ")), values
+	}
+
+	var (
+		ors  []string
+		args []any
+	)
+	for i, k := range keys {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = ?", dialect.Quote(keys[j].Column)))
+			args = append(args, values[j])
+		}
+		op := "<"
+		if greater(k) {
+			op = ">"
+		}
+		ands = append(ands, fmt.Sprintf("%s %s ?", dialect.Quote(k.Column), op))
+		args = append(args, values[i])
+		ors = append(ors, fmt.Sprintf("(%s)", strings.Join(ands, " AND ")))
+	}
+	return strings.Join(ors, " OR "), args
+}
+
+// buildKeysetOrderBy 构建 ORDER BY 子句，向前翻页时方向取反
+func buildKeysetOrderBy(dialect Dialect, keys []SortKey, backward bool) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		order := "ASC"
+		if k.Desc != backward {
+			order = "DESC"
+		}
+		parts[i] = fmt.Sprintf("%s %s", dialect.Quote(k.Column), order)
+	}
+	return "ORDER BY " + strings.Join(parts, ", ")
+}
+
+func (s CursorPageEndPoint[T]) point2Sql(dialect Dialect, values []any, backward bool) (string, []any, error) {
+	if s.Limit < 1 {
+		return "", nil, errors.New("limit must be >= 1")
+	}
+	if len(s.SortKeys) == 0 {
+		return "", nil, errors.New("empty sort keys")
+	}
+	for _, k := range s.SortKeys {
+		if err := validateIdentifier(k.Column); err != nil {
+			return "", nil, err
+		}
+	}
+
+	fieldsQuery := buildFieldsClause(dialect, s.Fields)
+
+	tableQuery, joinArgs, err := buildFromClause(dialect, s.Table, s.Joins)
+	if err != nil {
+		return "", nil, err
+	}
+
+	conditionsQuery, conditionsArgs, err := buildCondition(dialect, s.Conditions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var where []string
+	args := joinArgs
+	if conditionsQuery != "" {
+		where = append(where, fmt.Sprintf("(%s)", conditionsQuery))
+		args = append(args, conditionsArgs...)
+	}
+	if values != nil {
+		keysetQuery, keysetArgs := buildKeysetClause(dialect, s.SortKeys, values, backward)
+		where = append(where, fmt.Sprintf("(%s)", keysetQuery))
+		args = append(args, keysetArgs...)
+	}
+
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString(fmt.Sprintf("SELECT %v FROM %v", fieldsQuery, tableQuery))
+	if len(where) > 0 {
+		queryBuilder.WriteString(" WHERE ")
+		queryBuilder.WriteString(strings.Join(where, " AND "))
+	}
+	queryBuilder.WriteString(" ")
+	queryBuilder.WriteString(buildKeysetOrderBy(dialect, s.SortKeys, backward))
+	// 多取一行用于判断是否还有下一页
+	queryBuilder.WriteString(" ")
+	queryBuilder.WriteString(dialect.LimitOffset(int64(s.Limit)+1, 0, true))
+
+	return queryBuilder.String(), args, nil
+}
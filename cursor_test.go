@@ -0,0 +1,105 @@
+package db_dao
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- cursor_test.go: Tests for keyset pagination SQL and cursor tokens ---
+
+func TestBuildKeysetClause(t *testing.T) {
+	keys := []SortKey{{Column: "created_at"}, {Column: "id"}}
+	values := []any{"2026-01-01", 7}
+
+	t.Run("row values", func(t *testing.T) {
+		query, args := buildKeysetClause(Postgres, keys, values, false)
+		assert.Equal(t, `("created_at", "id") > (?, ?)`, query)
+		assert.Equal(t, values, args)
+
+		query, _ = buildKeysetClause(Postgres, keys, values, true)
+		assert.Equal(t, `("created_at", "id") < (?, ?)`, query)
+	})
+
+	t.Run("expanded form without row value support", func(t *testing.T) {
+		query, args := buildKeysetClause(SQLServer, keys, values, false)
+		assert.Equal(t, `([created_at] > ?) OR ([created_at] = ? AND [id] > ?)`, query)
+		assert.Equal(t, []any{"2026-01-01", "2026-01-01", 7}, args)
+	})
+
+	t.Run("expanded form for mixed directions", func(t *testing.T) {
+		mixed := []SortKey{{Column: "age", Desc: true}, {Column: "id"}}
+		query, _ := buildKeysetClause(Postgres, mixed, []any{30, 1}, false)
+		assert.Equal(t, `("age" < ?) OR ("age" = ? AND "id" > ?)`, query)
+
+		query, _ = buildKeysetClause(Postgres, mixed, []any{30, 1}, true)
+		assert.Equal(t, `("age" > ?) OR ("age" = ? AND "id" < ?)`, query)
+	})
+}
+
+func TestCursorPageEndPoint_point2Sql(t *testing.T) {
+	ep := CursorPageEndPoint[User]{
+		Table:      "users",
+		Conditions: map[string]any{"age": Gt(18)},
+		SortKeys:   []SortKey{{Column: "age", Desc: true}, {Column: "id", Desc: true}},
+		Limit:      10,
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		query, args, err := ep.point2Sql(SQLite, nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM `users` WHERE ((`age` > ?)) ORDER BY `age` DESC, `id` DESC LIMIT 11 OFFSET 0", query)
+		assert.Equal(t, []any{18}, args)
+	})
+
+	t.Run("previous page", func(t *testing.T) {
+		query, args, err := ep.point2Sql(SQLite, []any{30, 5}, true)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM `users` WHERE ((`age` > ?)) AND ((`age`, `id`) > (?, ?)) ORDER BY `age` ASC, `id` ASC LIMIT 11 OFFSET 0", query)
+		assert.Equal(t, []any{18, 30, 5}, args)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		bad := ep
+		bad.Limit = 0
+		_, _, err := bad.point2Sql(SQLite, nil, false)
+		assert.Error(t, err)
+
+		bad = ep
+		bad.SortKeys = nil
+		_, _, err = bad.point2Sql(SQLite, nil, false)
+		assert.Error(t, err)
+
+		bad = ep
+		bad.SortKeys = []SortKey{{Column: "id; DROP TABLE users"}}
+		_, _, err = bad.point2Sql(SQLite, nil, false)
+		assert.Error(t, err)
+	})
+}
+
+func TestCursorToken(t *testing.T) {
+	secret := []byte("secret")
+	token, err := encodeCursor(secret, "users|id", cursorNext, []any{int64(42)})
+	require.NoError(t, err)
+
+	p, err := decodeCursor(secret, "users|id", token)
+	require.NoError(t, err)
+	assert.Equal(t, cursorNext, p.Direction)
+	assert.Equal(t, []json.RawMessage{json.RawMessage("42")}, p.Values)
+
+	_, err = decodeCursor([]byte("other"), "users|id", token)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeCursor(secret, "orders|id", token)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	tampered := []byte(token)
+	tampered[3] ^= 1
+	_, err = decodeCursor(secret, "users|id", string(tampered))
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeCursor(secret, "users|id", "garbage")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
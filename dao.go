@@ -3,6 +3,10 @@ package db_dao
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"reflect"
+	"slices"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -10,21 +14,42 @@ import (
 // DAO is the main data access object, generic over a model type T.
 // It holds an Executor, which can be either a *sqlx.DB or a *sqlx.Tx.
 type DAO[T any] struct {
-	db Executor
+	db    Executor
+	cfg   *config
+	scope trashedScope
+	txn   *txLevel // txn 由 BeginTx 设置，记录嵌套事务的层
 }
 
 // NewDAO creates a new DAO for a specific model type.
-func NewDAO[T any](db Executor) *DAO[T] {
-	return &DAO[T]{db: db}
+// The SQL dialect is detected from the driver name unless WithDialect is given.
+func NewDAO[T any](db Executor, opts ...Option) *DAO[T] {
+	return &DAO[T]{db: db, cfg: newConfig(db, opts)}
 }
 
 // 确保 DAO[T] 实现了 IDAO[T] 接口
 var _ IDAO[any] = (*DAO[any])(nil)
 
+// ErrUnsupportedDAO is returned by the package-level helpers (SelectAs, GetAs, Pluck, Aggregate, TxOf)
+// when the IDAO passed to them was not created by this package, e.g. a mock.
+var ErrUnsupportedDAO = errors.New("IDAO is not backed by a *DAO")
+
+// daoOf 取出 IDAO 背后的 *DAO，供包级辅助函数使用 (NewDAO、BeginTx、WithTx、Use、WithTrashed 返回的都是 *DAO)
+func daoOf[T any](dao IDAO[T]) (*DAO[T], error) {
+	d, ok := dao.(*DAO[T])
+	if !ok || d == nil {
+		return nil, ErrUnsupportedDAO
+	}
+	return d, nil
+}
+
 // BeginTx starts a transaction.
+// On a DAO that is already a transaction, it creates a savepoint instead and returns a DAO whose
+// Commit releases the savepoint and whose Rollback undoes only the work done since it, so functions
+// that need their own transaction can run inside an outer one. Only the outermost Commit commits;
+// opts apply to the outermost transaction only.
 func (d *DAO[T]) BeginTx(ctx context.Context, opts ...*sql.TxOptions) (IDAO[T], error) {
-	// Only a *sqlx.DB can begin a transaction.
-	if db, ok := d.db.(*sqlx.DB); ok {
+	switch db := d.db.(type) {
+	case *sqlx.DB:
 		var txOpts *sql.TxOptions
 		if len(opts) > 0 {
 			txOpts = opts[0]
@@ -33,26 +58,27 @@ func (d *DAO[T]) BeginTx(ctx context.Context, opts ...*sql.TxOptions) (IDAO[T],
 		if err != nil {
 			return nil, err
 		}
-		return &DAO[T]{db: tx}, nil
+		return &DAO[T]{db: tx, cfg: d.cfg, scope: d.scope, txn: newTxLevel()}, nil
+	case *sqlx.Tx:
+		level, err := beginLevel(ctx, db, d.cfg.dialect, d.txn)
+		if err != nil {
+			return nil, err
+		}
+		return &DAO[T]{db: db, cfg: d.cfg, scope: d.scope, txn: level}, nil
 	}
-	// If it's already a transaction, return an error or handle as needed.
 	return nil, sql.ErrTxDone
 }
 
-// Commit commits the transaction.
+// Commit commits the transaction, or releases the savepoint of a nested transaction.
+// Committing a level also ends the levels nested in it.
 func (d *DAO[T]) Commit() error {
-	if tx, ok := d.db.(*sqlx.Tx); ok {
-		return tx.Commit()
-	}
-	return sql.ErrTxDone
+	return d.endTx(context.Background(), false)
 }
 
-// Rollback rollbacks the transaction.
+// Rollback rollbacks the transaction, or rolls back to the savepoint of a nested transaction.
+// Rolling back a level also ends the levels nested in it.
 func (d *DAO[T]) Rollback() error {
-	if tx, ok := d.db.(*sqlx.Tx); ok {
-		return tx.Rollback()
-	}
-	return sql.ErrTxDone
+	return d.endTx(context.Background(), true)
 }
 
 // GetExecutor returns the underlying executor.
@@ -60,44 +86,90 @@ func (d *DAO[T]) GetExecutor() Executor {
 	return d.db
 }
 
-// rebind applies the correct bindvar type for the driver.
+// Dialect returns the SQL dialect used by the DAO.
+func (d *DAO[T]) Dialect() Dialect {
+	return d.cfg.dialect
+}
+
+// rebind applies the correct bindvar type for the dialect.
 func (d *DAO[T]) rebind(query string) string {
-	if db, ok := d.db.(*sqlx.DB); ok {
-		return db.Rebind(query)
-	}
-	if tx, ok := d.db.(*sqlx.Tx); ok {
-		return tx.Rebind(query)
-	}
-	return query
+	return d.cfg.dialect.Rebind(query)
 }
 
 // Get executes a get query.
 func (d *DAO[T]) Get(ctx context.Context, endpoint GetEndPoint[T]) error {
-	query, args, err := endpoint.point2Sql()
+	table, query, args, err := d.getQuery(endpoint)
 	if err != nil {
 		return err
 	}
-	return sqlx.GetContext(ctx, d.db, endpoint.Model, d.rebind(query), args...)
+	return d.getContext(ctx, "Get", table, endpoint.Model, query, args)
+}
+
+// getQuery 补全表名与字段、校验列并追加软删除过滤，返回表名与查询语句
+func (d *DAO[T]) getQuery(endpoint GetEndPoint[T]) (string, string, []any, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{fields: endpoint.Fields, conditions: endpoint.Conditions, joins: endpoint.Joins, appends: endpoint.Appends, compiled: endpoint.compiled}); err != nil {
+		return "", "", nil, err
+	}
+	qualifier := tableQualifier(endpoint.Table, endpoint.Joins)
+	endpoint.Fields = d.fields(endpoint.Fields, qualifier)
+	conditions, err := d.readConditions(endpoint.Table, qualifier, endpoint.Conditions)
+	if err != nil {
+		return "", "", nil, err
+	}
+	endpoint.Conditions = conditions
+	query, args, err := endpoint.point2Sql(d.cfg.dialect)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return endpoint.Table, query, args, nil
 }
 
 // Select executes a select query.
 func (d *DAO[T]) Select(ctx context.Context, endpoint SelectEndPoint[T]) error {
-	query, args, err := endpoint.point2Sql()
+	table, query, args, err := d.selectQuery(endpoint)
 	if err != nil {
 		return err
 	}
-	return sqlx.SelectContext(ctx, d.db, endpoint.Model, d.rebind(query), args...)
+	return d.selectContext(ctx, "Select", table, endpoint.Model, query, args)
+}
+
+// selectQuery 补全表名与字段、校验列并追加软删除过滤，返回表名与查询语句
+func (d *DAO[T]) selectQuery(endpoint SelectEndPoint[T]) (string, string, []any, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{fields: endpoint.Fields, conditions: endpoint.Conditions, joins: endpoint.Joins, appends: endpoint.Appends, compiled: endpoint.compiled}); err != nil {
+		return "", "", nil, err
+	}
+	qualifier := tableQualifier(endpoint.Table, endpoint.Joins)
+	endpoint.Fields = d.fields(endpoint.Fields, qualifier)
+	conditions, err := d.readConditions(endpoint.Table, qualifier, endpoint.Conditions)
+	if err != nil {
+		return "", "", nil, err
+	}
+	endpoint.Conditions = conditions
+	query, args, err := endpoint.point2Sql(d.cfg.dialect)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return endpoint.Table, query, args, nil
 }
 
 // Paginate executes a paginated query.
 func (d *DAO[T]) Paginate(ctx context.Context, endpoint PageEndPoint[T]) (int64, error) {
-	var total int64
-	query, args, err := endpoint.point2Sql()
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{fields: endpoint.Fields, sortField: endpoint.SortField, sortKeys: endpoint.SortKeys, conditions: endpoint.Conditions, joins: endpoint.Joins}); err != nil {
+		return 0, err
+	}
+	qualifier := tableQualifier(endpoint.Table, endpoint.Joins)
+	endpoint.Fields = d.fields(endpoint.Fields, qualifier)
+	conditions, err := d.readConditions(endpoint.Table, qualifier, endpoint.Conditions)
 	if err != nil {
 		return 0, err
 	}
+	endpoint.Conditions = conditions
 
-	if err := sqlx.GetContext(ctx, d.db, &total, d.rebind(query), args...); err != nil {
+	total, err := d.count(ctx, "Paginate", endpoint)
+	if err != nil {
 		return 0, err
 	}
 
@@ -105,59 +177,511 @@ func (d *DAO[T]) Paginate(ctx context.Context, endpoint PageEndPoint[T]) (int64,
 		return 0, nil
 	}
 
-	query, args, err = endpoint.point2pageSql()
+	query, args, err := endpoint.point2pageSql(d.cfg.dialect)
 	if err != nil {
 		return 0, err
 	}
 
-	return total, sqlx.SelectContext(ctx, d.db, endpoint.Model, d.rebind(query), args...)
+	return total, d.selectContext(ctx, "Paginate", endpoint.Table, endpoint.Model, query, args)
+}
+
+// readConditions 为查询条件追加软删除过滤，并包装敏感列的参数
+func (d *DAO[T]) readConditions(table, qualifier string, conditions Condition) (Condition, error) {
+	conditions, err := d.scoped(conditions, qualifier)
+	if err != nil {
+		return nil, err
+	}
+	return redactCondition(d.sensitiveSet(table), conditions), nil
+}
+
+// count executes the COUNT(*) query of an endpoint whose Table and Conditions are already prepared.
+func (d *DAO[T]) count(ctx context.Context, op string, endpoint PageEndPoint[T]) (int64, error) {
+	query, args, err := endpoint.point2Sql(d.cfg.dialect)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	if err := d.getContext(ctx, op, endpoint.Table, &total, query, args); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// PaginateCursor executes a keyset-paginated query.
+// It orders by endpoint.SortKeys and continues after (or before) the row encoded in endpoint.Cursor,
+// replacing *endpoint.Model with at most endpoint.Limit rows. The returned tokens are signed,
+// so a tampered cursor or one issued for another table / sort order yields ErrInvalidCursor.
+func (d *DAO[T]) PaginateCursor(ctx context.Context, endpoint CursorPageEndPoint[T]) (CursorPage, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{fields: endpoint.Fields, sortKeys: endpoint.SortKeys, conditions: endpoint.Conditions, joins: endpoint.Joins}); err != nil {
+		return CursorPage{}, err
+	}
+	qualifier := tableQualifier(endpoint.Table, endpoint.Joins)
+	endpoint.Fields = d.fields(endpoint.Fields, qualifier)
+	var page CursorPage
+	if endpoint.Model == nil {
+		return page, errors.New("nil model")
+	}
+	conditions, err := d.scoped(endpoint.Conditions, qualifier)
+	if err != nil {
+		return page, err
+	}
+	endpoint.Conditions = conditions
+	fields, err := endpoint.sortKeyFields(mapperOf(d.db))
+	if err != nil {
+		return page, err
+	}
+
+	fingerprint, err := endpoint.fingerprint(d.cfg.dialect)
+	if err != nil {
+		return page, err
+	}
+	var (
+		values   []any
+		backward bool
+	)
+	if endpoint.Cursor != "" {
+		cursor, err := decodeCursor(d.cfg.cursorSecret, fingerprint, endpoint.Cursor)
+		if err != nil {
+			return page, err
+		}
+		if values, err = decodeValues(fields, cursor.Values); err != nil {
+			return page, err
+		}
+		backward = cursor.Direction == cursorPrev
+	}
+	if sensitive := d.sensitiveSet(endpoint.Table); sensitive != nil {
+		endpoint.Conditions = redactCondition(sensitive, endpoint.Conditions)
+		for i, k := range endpoint.SortKeys {
+			if values != nil && sensitive[keyColumn(k.Column)] {
+				values[i] = redactValue(values[i])
+			}
+		}
+	}
+
+	query, args, err := endpoint.point2Sql(d.cfg.dialect, values, backward)
+	if err != nil {
+		return page, err
+	}
+	var rows []T
+	if err := d.selectContext(ctx, "PaginateCursor", endpoint.Table, &rows, query, args); err != nil {
+		return page, err
+	}
+
+	hasMore := len(rows) > int(endpoint.Limit)
+	if hasMore {
+		rows = rows[:endpoint.Limit]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+	*endpoint.Model = rows
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	// 向后翻页时，"还有更多" 指向下一页；向前翻页时指向上一页。
+	// 另一个方向只要是通过游标到达的，就一定存在。
+	hasNext, hasPrev := hasMore, endpoint.Cursor != ""
+	if backward {
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		if page.Next, err = encodeCursor(d.cfg.cursorSecret, fingerprint, cursorNext, rowValues(fields, &rows[len(rows)-1])); err != nil {
+			return page, err
+		}
+	}
+	if hasPrev {
+		if page.Prev, err = encodeCursor(d.cfg.cursorSecret, fingerprint, cursorPrev, rowValues(fields, &rows[0])); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
+
+// newEvent creates the QueryEvent passed to hooks, with the rebinding-applied SQL.
+func (d *DAO[T]) newEvent(op, table, query string, args []any) *QueryEvent {
+	return &QueryEvent{Operation: op, Table: table, SQL: d.rebind(query), Args: args}
+}
+
+// execResult executes a query through the hooks and returns the driver result.
+func (d *DAO[T]) execResult(ctx context.Context, op, table, query string, args []any) (sql.Result, error) {
+	var result sql.Result
+	err := d.cfg.runQuery(ctx, d.newEvent(op, table, query, args), func(ctx context.Context, e *QueryEvent) (int64, error) {
+		var err error
+		if result, err = d.db.ExecContext(ctx, e.SQL, unredact(e.Args)...); err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	})
+	return result, err
 }
 
 // execContext executes a query that returns rows affected.
-func (d *DAO[T]) execContext(ctx context.Context, query string, args ...any) (int64, error) {
-	result, err := d.db.ExecContext(ctx, d.rebind(query), args...)
+func (d *DAO[T]) execContext(ctx context.Context, op, table, query string, args []any) (int64, error) {
+	result, err := d.execResult(ctx, op, table, query, args)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
+// getContext executes a query that scans a single row into dest.
+func (d *DAO[T]) getContext(ctx context.Context, op, table string, dest any, query string, args []any) error {
+	return d.cfg.runQuery(ctx, d.newEvent(op, table, query, args), func(ctx context.Context, e *QueryEvent) (int64, error) {
+		if err := sqlx.GetContext(ctx, d.db, dest, e.SQL, unredact(e.Args)...); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	})
+}
+
+// selectContext executes a query that scans all rows into the slice pointed to by dest.
+func (d *DAO[T]) selectContext(ctx context.Context, op, table string, dest any, query string, args []any) error {
+	return d.cfg.runQuery(ctx, d.newEvent(op, table, query, args), func(ctx context.Context, e *QueryEvent) (int64, error) {
+		if err := sqlx.SelectContext(ctx, d.db, dest, e.SQL, unredact(e.Args)...); err != nil {
+			return 0, err
+		}
+		return int64(reflect.Indirect(reflect.ValueOf(dest)).Len()), nil
+	})
+}
+
+// queryxContext executes a query and returns the open rows. Hooks receive AfterQuery when the rows
+// are closed, with the number of rows read and the first error met while reading them.
+func (d *DAO[T]) queryxContext(ctx context.Context, op, table, query string, args []any) (*hookedRows, error) {
+	event := d.newEvent(op, table, query, args)
+	run, err := d.cfg.beforeQuery(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.db.QueryxContext(run.ctx, event.SQL, unredact(event.Args)...)
+	if err != nil {
+		run.after(0, err)
+		return nil, err
+	}
+	return &hookedRows{Rows: rows, run: run}, nil
+}
+
 // Insert executes an insert query.
 func (d *DAO[T]) Insert(ctx context.Context, endpoint InsertEndpoint[T]) (int64, error) {
-	query, args, err := endpoint.point2Sql()
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{rows: []map[string]any{endpoint.Rows}}); err != nil {
+		return 0, err
+	}
+	endpoint.Rows = d.stampInsert(endpoint.Rows)
+	endpoint.Rows = redactRow(d.sensitiveSet(endpoint.Table), endpoint.Rows)
+	query, args, err := endpoint.point2Sql(d.cfg.dialect)
 	if err != nil {
 		return 0, err
 	}
-	return d.execContext(ctx, query, args...)
+	return d.execContext(ctx, "Insert", endpoint.Table, query, args)
 }
 
 // BatchInsert executes a batch insert query.
+// Rows are split into several statements when they exceed the dialect's bind parameter limit
+// (or endpoint.ChunkSize). Chunks run in order and the total rows affected is returned; when a
+// chunk fails the rows affected by the previous chunks are returned with a *BatchError, unless
+// endpoint.Atomic is set, in which case all chunks run in one transaction (a savepoint when d is
+// already a transaction) and are rolled back. Atomic requires a *sqlx.DB or *sqlx.Tx executor.
 func (d *DAO[T]) BatchInsert(ctx context.Context, endpoint BatchInsertEndpoint[T]) (int64, error) {
-	query, args, err := endpoint.point2Sql()
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{rows: endpoint.Rows}); err != nil {
+		return 0, err
+	}
+	endpoint.Rows = d.stampBatchInsert(endpoint.Rows)
+	endpoint.Rows = redactRows(d.sensitiveSet(endpoint.Table), endpoint.Rows)
+	chunks, err := d.batchChunks(endpoint.Rows, endpoint.ChunkSize, func(rows []map[string]any) (string, []any, error) {
+		return BatchInsertEndpoint[T]{Table: endpoint.Table, Rows: rows}.point2Sql(d.cfg.dialect)
+	})
 	if err != nil {
 		return 0, err
 	}
-	return d.execContext(ctx, query, args...)
+	if endpoint.Atomic {
+		return d.execChunksAtomic(ctx, endpoint.Table, chunks)
+	}
+	return d.execChunks(ctx, "BatchInsert", endpoint.Table, chunks)
+}
+
+// InsertReturning executes an insert query and writes the generated key(s) back into endpoint.Model.
+// Dialects that support returning (Postgres RETURNING, SQL Server OUTPUT INSERTED) scan the Returning
+// columns into Model; the others (SQLite/MySQL) assign LastInsertId to the single Returning column.
+func (d *DAO[T]) InsertReturning(ctx context.Context, endpoint InsertEndpoint[T]) (int64, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{rows: []map[string]any{endpoint.Rows}, returning: endpoint.Returning}); err != nil {
+		return 0, err
+	}
+	if endpoint.Model == nil {
+		return 0, errors.New("nil model")
+	}
+	if len(endpoint.Returning) == 0 {
+		return 0, errors.New("empty returning columns")
+	}
+	endpoint.Rows = d.stampInsert(endpoint.Rows)
+	endpoint.Rows = redactRow(d.sensitiveSet(endpoint.Table), endpoint.Rows)
+	query, args, err := endpoint.point2Sql(d.cfg.dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	if d.cfg.dialect.SupportsReturning() {
+		query = d.cfg.dialect.Returning(query, endpoint.Returning)
+		if err := d.getContext(ctx, "InsertReturning", endpoint.Table, endpoint.Model, query, args); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	if len(endpoint.Returning) > 1 {
+		return 0, errors.New("LastInsertId supports a single returning column")
+	}
+	result, err := d.execResult(ctx, "InsertReturning", endpoint.Table, query, args)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := setInsertID(mapperOf(d.db), reflect.ValueOf(endpoint.Model).Elem(), endpoint.Returning[0], id); err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// BatchInsertReturning executes a batch insert query and writes the generated key(s) of every row
+// back into endpoint.Model, in the same order as endpoint.Rows. An empty Model is grown to len(Rows).
+// Rows are split into chunks like BatchInsert (endpoint.Atomic is not supported); when a chunk fails
+// the models of the previous chunks keep their keys and a *BatchError is returned.
+func (d *DAO[T]) BatchInsertReturning(ctx context.Context, endpoint BatchInsertEndpoint[T]) (int64, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{rows: endpoint.Rows, returning: endpoint.Returning}); err != nil {
+		return 0, err
+	}
+	if len(endpoint.Returning) == 0 {
+		return 0, errors.New("empty returning columns")
+	}
+	if !d.cfg.dialect.SupportsReturning() && len(endpoint.Returning) > 1 {
+		return 0, errors.New("LastInsertId supports a single returning column")
+	}
+	endpoint.Rows = d.stampBatchInsert(endpoint.Rows)
+	endpoint.Rows = redactRows(d.sensitiveSet(endpoint.Table), endpoint.Rows)
+	chunks, err := d.batchChunks(endpoint.Rows, endpoint.ChunkSize, func(rows []map[string]any) (string, []any, error) {
+		return BatchInsertEndpoint[T]{Table: endpoint.Table, Rows: rows}.point2Sql(d.cfg.dialect)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := resizeModels(endpoint.Model, len(endpoint.Rows)); err != nil {
+		return 0, err
+	}
+	models := *endpoint.Model
+	return runChunks(chunks, func(c chunk) (int64, error) {
+		return d.insertChunkReturning(ctx, endpoint.Table, c, endpoint.Returning, models[c.start:c.end])
+	})
+}
+
+// insertChunkReturning 执行一批 INSERT，并把生成的主键写回 models (与该批的行一一对应)
+func (d *DAO[T]) insertChunkReturning(ctx context.Context, table string, c chunk, returning []string, models []T) (int64, error) {
+	if d.cfg.dialect.SupportsReturning() {
+		query := d.cfg.dialect.Returning(c.query, returning)
+		rows, err := d.queryxContext(ctx, "BatchInsertReturning", table, query, c.args)
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+		var n int64
+		for rows.Next() {
+			if n >= int64(len(models)) {
+				err := errors.New("more returned rows than inserted rows")
+				rows.fail(err)
+				return n, err
+			}
+			if err := rows.StructScan(&models[n]); err != nil {
+				rows.fail(err)
+				return n, err
+			}
+			n++
+		}
+		return n, rows.Err()
+	}
+
+	result, err := d.execResult(ctx, "BatchInsertReturning", table, c.query, c.args)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return affected, err
+	}
+	m := mapperOf(d.db)
+	for i, id := range batchInsertIDs(d.cfg.dialect, lastID, len(models)) {
+		if err := setInsertID(m, reflect.ValueOf(&models[i]).Elem(), returning[0], id); err != nil {
+			return affected, err
+		}
+	}
+	return affected, nil
+}
+
+// InsertModel inserts a single model, deriving columns and values from T's db tags.
+// An empty table is derived from T like an endpoint's Table.
+// Fields tagged with omitempty (e.g. `db:"id,omitempty"`) are skipped when zero.
+func (d *DAO[T]) InsertModel(ctx context.Context, table string, model *T) (int64, error) {
+	if model == nil {
+		return 0, errors.New("nil model")
+	}
+	meta := d.meta()
+	if meta.err != nil {
+		return 0, meta.err
+	}
+	row, err := modelToRow(meta.fields, reflect.ValueOf(model).Elem())
+	if err != nil {
+		return 0, err
+	}
+	return d.Insert(ctx, InsertEndpoint[T]{Table: table, Rows: row})
+}
+
+// BatchInsertModels inserts multiple models in a single statement, deriving columns from T's db tags.
+// An omitempty field is skipped only when it is zero in every model.
+func (d *DAO[T]) BatchInsertModels(ctx context.Context, table string, models []T) (int64, error) {
+	meta := d.meta()
+	if meta.err != nil {
+		return 0, meta.err
+	}
+	rows, err := modelsToRows(meta.fields, reflect.ValueOf(models))
+	if err != nil {
+		return 0, err
+	}
+	return d.BatchInsert(ctx, BatchInsertEndpoint[T]{Table: table, Rows: rows})
+}
+
+// stampInsert 为单行插入填充创建 / 更新时间
+func (d *DAO[T]) stampInsert(row map[string]any) map[string]any {
+	createdAt, updatedAt := d.timestampColumns()
+	if createdAt == "" && updatedAt == "" {
+		return row
+	}
+	return stampRow(row, d.cfg.now(), createdAt, updatedAt)
+}
+
+// stampBatchInsert 为批量插入的每一行填充创建 / 更新时间
+func (d *DAO[T]) stampBatchInsert(rows []map[string]any) []map[string]any {
+	createdAt, updatedAt := d.timestampColumns()
+	if createdAt == "" && updatedAt == "" {
+		return rows
+	}
+	return stampRows(rows, d.cfg.now(), createdAt, updatedAt)
+}
+
+// stampUpdate 为 UPDATE 的 SET 填充更新时间 (Update、软删除与 Restore 共用)
+func (d *DAO[T]) stampUpdate(row map[string]any, now time.Time) map[string]any {
+	if _, updatedAt := d.timestampColumns(); updatedAt != "" {
+		return stampRow(row, now, updatedAt)
+	}
+	return row
+}
+
+// Upsert executes an insert-or-update query.
+func (d *DAO[T]) Upsert(ctx context.Context, endpoint UpsertEndpoint[T]) (int64, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{rows: []map[string]any{endpoint.Rows}, conflict: endpoint.ConflictColumns, update: endpoint.UpdateColumns}); err != nil {
+		return 0, err
+	}
+	if createdAt, updatedAt := d.timestampColumns(); createdAt != "" || updatedAt != "" {
+		endpoint.Rows = stampRow(endpoint.Rows, d.cfg.now(), createdAt, updatedAt)
+		endpoint.UpdateColumns = upsertUpdateColumns(sortedKeys(endpoint.Rows), endpoint.ConflictColumns, endpoint.UpdateColumns, createdAt, updatedAt)
+	}
+	endpoint.Rows = redactRow(d.sensitiveSet(endpoint.Table), endpoint.Rows)
+	query, args, err := endpoint.point2Sql(d.cfg.dialect)
+	if err != nil {
+		return 0, err
+	}
+	return d.execContext(ctx, "Upsert", endpoint.Table, query, args)
+}
+
+// BatchUpsert executes a batch insert-or-update query.
+// Rows are split into several statements when they exceed the dialect's bind parameter limit;
+// chunks run in order and a failing chunk is reported as a *BatchError, as in BatchInsert.
+func (d *DAO[T]) BatchUpsert(ctx context.Context, endpoint BatchUpsertEndpoint[T]) (int64, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{rows: endpoint.Rows, conflict: endpoint.ConflictColumns, update: endpoint.UpdateColumns}); err != nil {
+		return 0, err
+	}
+	if createdAt, updatedAt := d.timestampColumns(); (createdAt != "" || updatedAt != "") && len(endpoint.Rows) > 0 {
+		endpoint.Rows = stampRows(endpoint.Rows, d.cfg.now(), createdAt, updatedAt)
+		endpoint.UpdateColumns = upsertUpdateColumns(sortedKeys(endpoint.Rows[0]), endpoint.ConflictColumns, endpoint.UpdateColumns, createdAt, updatedAt)
+	}
+	endpoint.Rows = redactRows(d.sensitiveSet(endpoint.Table), endpoint.Rows)
+	chunks, err := d.batchChunks(endpoint.Rows, 0, func(rows []map[string]any) (string, []any, error) {
+		part := endpoint
+		part.Rows = rows
+		return part.point2Sql(d.cfg.dialect)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return d.execChunks(ctx, "BatchUpsert", endpoint.Table, chunks)
 }
 
 // Update executes an update query.
+// When T has a field tagged `dao:"version"`, endpoint.Rows must hold the version that was read:
+// it is matched in the WHERE clause, incremented in the SET clause, and ErrStaleObject is
+// returned when no row is affected.
 func (d *DAO[T]) Update(ctx context.Context, endpoint UpdateEndPoint[T]) (int64, error) {
-	query, rowsArgs, conditionsArgs, err := endpoint.point2Sql()
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{rows: []map[string]any{endpoint.Rows}, conditions: endpoint.Conditions, appends: endpoint.Appends}); err != nil {
+		return 0, err
+	}
+	endpoint.Rows = d.stampUpdate(endpoint.Rows, d.cfg.now())
+	column := d.versionColumn()
+	if column == "" {
+		return d.update(ctx, "Update", endpoint)
+	}
+	endpoint, err := versioned(d.cfg.dialect, column, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := d.update(ctx, "Update", endpoint)
+	if err == nil && affected == 0 {
+		return 0, ErrStaleObject
+	}
+	return affected, err
+}
+
+// update executes an update query, reporting it to hooks as op.
+func (d *DAO[T]) update(ctx context.Context, op string, endpoint UpdateEndPoint[T]) (int64, error) {
+	sensitive := d.sensitiveSet(endpoint.Table)
+	endpoint.Rows = redactRow(sensitive, endpoint.Rows)
+	endpoint.Conditions = redactCondition(sensitive, endpoint.Conditions)
+	query, args, err := endpoint.buildStatement(d.cfg.dialect)
 	if err != nil {
 		return 0, err
 	}
-	// Use explicit new slice to avoid mutating rowsArgs when it has spare capacity.
-	args := make([]any, 0, len(rowsArgs)+len(conditionsArgs))
-	args = append(args, rowsArgs...)
-	args = append(args, conditionsArgs...)
-	return d.execContext(ctx, query, args...)
+	return d.execContext(ctx, op, endpoint.Table, query, args)
 }
 
 // Delete executes a delete query.
+// When soft deletion is enabled it sets the soft-delete column of the matching rows instead.
 func (d *DAO[T]) Delete(ctx context.Context, endpoint DeleteEndPoint[T]) (int64, error) {
-	query, args, err := endpoint.point2Sql()
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{conditions: endpoint.Conditions}); err != nil {
+		return 0, err
+	}
+	if column := d.softDeleteColumn(); column != "" {
+		return d.softDelete(ctx, column, endpoint)
+	}
+	return d.delete(ctx, "Delete", endpoint)
+}
+
+// delete executes a delete query, reporting it to hooks as op.
+func (d *DAO[T]) delete(ctx context.Context, op string, endpoint DeleteEndPoint[T]) (int64, error) {
+	endpoint.Conditions = redactCondition(d.sensitiveSet(endpoint.Table), endpoint.Conditions)
+	query, args, err := endpoint.point2Sql(d.cfg.dialect)
 	if err != nil {
 		return 0, err
 	}
-	return d.execContext(ctx, query, args...)
+	return d.execContext(ctx, op, endpoint.Table, query, args)
 }
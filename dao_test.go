@@ -3,15 +3,36 @@ package db_dao
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
 // --- Test Suite Setup ---
 
+const (
+	createUsersTable = `CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER)`
+	seedUsers        = `INSERT INTO users (id, name, age) VALUES (1, 'Alice', 30), (2, 'Bob', 40)`
+)
+
+// newSQLiteDB 创建一个内存 SQLite 数据库并依次执行 schema 中的语句 (建表、种子数据)，测试结束时关闭。
+// :memory: 的每个连接都是独立的数据库，因此只保留一个连接，事务与保存点测试也能看到同一份数据
+func newSQLiteDB(t *testing.T, schema ...string) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	for _, stmt := range schema {
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	return db
+}
+
 type DAOTestSuite struct {
 	suite.Suite
 	db      *sqlx.DB
@@ -42,11 +63,11 @@ func (s *DAOTestSuite) SetupTest() {
 	// 每个测试开始前，都创建一个干净的表
 	_, err := s.db.Exec(`DROP TABLE IF EXISTS users`)
 	s.Require().NoError(err)
-	_, err = s.db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER)`)
+	_, err = s.db.Exec(createUsersTable)
 	s.Require().NoError(err)
 
 	// 插入一些种子数据
-	_, err = s.db.Exec(`INSERT INTO users (id, name, age) VALUES (1, 'Alice', 30), (2, 'Bob', 40)`)
+	_, err = s.db.Exec(seedUsers)
 	s.Require().NoError(err)
 }
 
@@ -210,8 +231,9 @@ func (s *DAOTestSuite) TestErrorHandling() {
 	err = s.userDAO.Rollback()
 	s.ErrorIs(err, sql.ErrTxDone)
 
-	// 在事务 DAO 上调用 BeginTx 应该返回错误
+	// 在已结束的事务 DAO 上调用 BeginTx 应该返回错误 (未结束时创建保存点，见 savepoint_test.go)
 	txDAO, _ := s.userDAO.BeginTx(context.Background())
+	s.Require().NoError(txDAO.Rollback())
 	_, err = txDAO.BeginTx(context.Background())
 	s.ErrorIs(err, sql.ErrTxDone)
 }
@@ -367,7 +389,12 @@ func (s *DAOTestSuite) TestPaginateWithInvalidPageSize() {
 
 func (s *DAOTestSuite) TestInsertEmptyTable() {
 	ctx := context.Background()
-	_, err := s.userDAO.Insert(ctx, InsertEndpoint[User]{
+	// 空表名由 T 推导，匿名类型无法推导时报错
+	_, err := NewDAO[struct {
+		Name string `db:"name"`
+	}](s.db).Insert(ctx, InsertEndpoint[struct {
+		Name string `db:"name"`
+	}]{
 		Table: "",
 		Rows:  map[string]any{"name": "Test"},
 	})
@@ -399,6 +426,26 @@ func (s *DAOTestSuite) TestDeleteEmptyConditions() {
 	s.Error(err)
 }
 
+func (s *DAOTestSuite) TestMisspelledColumnFails() {
+	ctx := context.Background()
+	_, err := s.userDAO.Delete(ctx, DeleteEndPoint[User]{
+		Table:      "users",
+		Conditions: map[string]any{"nmae": Ne("zzz")},
+	})
+	s.ErrorContains(err, "no such column")
+
+	_, err = s.userDAO.Update(ctx, UpdateEndPoint[User]{
+		Table:      "users",
+		Rows:       map[string]any{"age": 99},
+		Conditions: map[string]any{"nmae": Ne("zzz")},
+	})
+	s.ErrorContains(err, "no such column")
+
+	var count int
+	s.Require().NoError(s.db.Get(&count, "SELECT count(*) FROM users WHERE age <> 99"))
+	s.Equal(2, count)
+}
+
 func (s *DAOTestSuite) TestBeginTxWithOptions() {
 	ctx := context.Background()
 	txDAO, err := s.userDAO.BeginTx(ctx, &sql.TxOptions{
@@ -423,3 +470,432 @@ func (s *DAOTestSuite) TestGetNotFound() {
 	})
 	s.Error(err) // sql.ErrNoRows
 }
+
+type modelUser struct {
+	ID    int64  `db:"id,omitempty"`
+	Name  string `db:"name"`
+	Age   int    `db:"age"`
+	Extra string `db:"-"`
+}
+
+func (s *DAOTestSuite) TestInsertModel() {
+	ctx := context.Background()
+	dao := NewDAO[modelUser](s.db)
+	affected, err := dao.InsertModel(ctx, "users", &modelUser{Name: "Charlie", Age: 50, Extra: "ignored"})
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+
+	var user User
+	s.Require().NoError(s.db.Get(&user, "SELECT * FROM users WHERE name = 'Charlie'"))
+	s.Equal(int64(3), user.ID) // omitempty id is left to sqlite
+	s.Equal(50, user.Age)
+}
+
+func (s *DAOTestSuite) TestBatchInsertModels() {
+	ctx := context.Background()
+	dao := NewDAO[modelUser](s.db)
+	affected, err := dao.BatchInsertModels(ctx, "users", []modelUser{
+		{Name: "Charlie", Age: 50},
+		{Name: "Diana", Age: 60},
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(2), affected)
+
+	_, err = dao.BatchInsertModels(ctx, "users", []modelUser{
+		{ID: 10, Name: "Eve", Age: 20},
+		{Name: "Frank", Age: 21},
+	})
+	s.Error(err)
+
+	_, err = dao.BatchInsertModels(ctx, "users", nil)
+	s.Error(err)
+}
+
+func (s *DAOTestSuite) TestInsertReturning_LastInsertId() {
+	ctx := context.Background()
+	var user User
+	affected, err := s.userDAO.InsertReturning(ctx, InsertEndpoint[User]{
+		Table:     "users",
+		Rows:      map[string]any{"name": "Charlie", "age": 50},
+		Returning: []string{"id"},
+		Model:     &user,
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+	s.Equal(int64(3), user.ID)
+
+	_, err = s.userDAO.InsertReturning(ctx, InsertEndpoint[User]{
+		Table:     "users",
+		Rows:      map[string]any{"name": "Diana"},
+		Returning: []string{"id", "age"},
+		Model:     &user,
+	})
+	s.Error(err) // LastInsertId can only fill one column
+
+	_, err = s.userDAO.InsertReturning(ctx, InsertEndpoint[User]{
+		Table:     "users",
+		Rows:      map[string]any{"name": "Diana"},
+		Returning: []string{"id"},
+	})
+	s.Error(err) // nil model
+}
+
+func (s *DAOTestSuite) TestBatchInsertReturning_LastInsertId() {
+	ctx := context.Background()
+	var users []User
+	affected, err := s.userDAO.BatchInsertReturning(ctx, BatchInsertEndpoint[User]{
+		Table: "users",
+		Rows: []map[string]any{
+			{"name": "Charlie", "age": 50},
+			{"name": "Diana", "age": 60},
+		},
+		Returning: []string{"id"},
+		Model:     &users,
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(2), affected)
+	s.Require().Len(users, 2)
+	s.Equal(int64(3), users[0].ID)
+	s.Equal(int64(4), users[1].ID)
+}
+
+func (s *DAOTestSuite) TestInsertReturning_Returning() {
+	// SQLite understands both RETURNING and $N placeholders, so a "postgres"-named
+	// handle on the same connection pool exercises the RETURNING code path.
+	ctx := context.Background()
+	dao := NewDAO[User](sqlx.NewDb(s.db.DB, "postgres"))
+
+	var user User
+	affected, err := dao.InsertReturning(ctx, InsertEndpoint[User]{
+		Table:     "users",
+		Rows:      map[string]any{"name": "Charlie", "age": 50},
+		Returning: []string{"id", "name"},
+		Model:     &user,
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+	s.Equal(int64(3), user.ID)
+	s.Equal("Charlie", user.Name)
+
+	users := []User{{Name: "Diana", Age: 60}, {Name: "Eve", Age: 70}}
+	affected, err = dao.BatchInsertReturning(ctx, BatchInsertEndpoint[User]{
+		Table: "users",
+		Rows: []map[string]any{
+			{"name": "Diana", "age": 60},
+			{"name": "Eve", "age": 70},
+		},
+		Returning: []string{"id"},
+		Model:     &users,
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(2), affected)
+	s.Equal(User{ID: 4, Name: "Diana", Age: 60}, users[0])
+	s.Equal(User{ID: 5, Name: "Eve", Age: 70}, users[1])
+}
+
+func (s *DAOTestSuite) TestUpsert() {
+	ctx := context.Background()
+	affected, err := s.userDAO.Upsert(ctx, UpsertEndpoint[User]{
+		Table:           "users",
+		Rows:            map[string]any{"id": 1, "name": "Alice", "age": 35},
+		ConflictColumns: []string{"id"},
+		UpdateColumns:   []string{"age"},
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+
+	var user User
+	s.Require().NoError(s.db.Get(&user, "SELECT * FROM users WHERE id = 1"))
+	s.Equal(35, user.Age)
+
+	affected, err = s.userDAO.Upsert(ctx, UpsertEndpoint[User]{
+		Table:     "users",
+		Rows:      map[string]any{"id": 2, "name": "Robert", "age": 41},
+		DoNothing: true,
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(0), affected)
+	s.Require().NoError(s.db.Get(&user, "SELECT * FROM users WHERE id = 2"))
+	s.Equal("Bob", user.Name)
+}
+
+func (s *DAOTestSuite) TestBatchUpsert() {
+	ctx := context.Background()
+	affected, err := s.userDAO.BatchUpsert(ctx, BatchUpsertEndpoint[User]{
+		Table: "users",
+		Rows: []map[string]any{
+			{"id": 2, "name": "Bobby", "age": 41},
+			{"id": 3, "name": "Charlie", "age": 50},
+		},
+		ConflictColumns: []string{"id"},
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(2), affected)
+
+	var users []User
+	s.Require().NoError(s.db.Select(&users, "SELECT * FROM users ORDER BY id"))
+	s.Require().Len(users, 3)
+	s.Equal("Bobby", users[1].Name)
+	s.Equal("Charlie", users[2].Name)
+}
+
+func (s *DAOTestSuite) TestSelectWithOperators() {
+	ctx := context.Background()
+	var users []User
+	err := s.userDAO.Select(ctx, SelectEndPoint[User]{
+		Model: &users,
+		Table: "users",
+		Conditions: map[string]any{
+			"age":  Between(25, 35),
+			"name": ILike("a%"),
+			"id":   NotIn(2, 3),
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(users, 1)
+	s.Equal("Alice", users[0].Name)
+}
+
+func (s *DAOTestSuite) TestNestedConditions() {
+	ctx := context.Background()
+	var users []User
+	// name = 'Alice' OR (age > 35 AND NOT id = 1)
+	err := s.userDAO.Select(ctx, SelectEndPoint[User]{
+		Model: &users,
+		Table: "users",
+		Conditions: AnyOf{
+			map[string]any{"name": Eq("Alice")},
+			And{
+				map[string]any{"age": Gt(35)},
+				Not{Condition: map[string]any{"id": Eq(1)}},
+			},
+		},
+		Appends: []string{"ORDER BY id"},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(users, 2)
+
+	affected, err := s.userDAO.Delete(ctx, DeleteEndPoint[User]{
+		Table:      "users",
+		Conditions: Not{Condition: map[string]any{"name": Eq("Alice")}},
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+}
+
+func (s *DAOTestSuite) TestPaginateCursor() {
+	ctx := context.Background()
+	_, err := s.db.Exec(`INSERT INTO users (id, name, age) VALUES (3, 'Charlie', 30), (4, 'Diana', 20), (5, 'Eve', 40)`)
+	s.Require().NoError(err)
+
+	dao := NewDAO[User](s.db, WithCursorSecret([]byte("test-secret")))
+	ep := CursorPageEndPoint[User]{
+		Table:    "users",
+		SortKeys: []SortKey{{Column: "age"}, {Column: "id"}},
+		Limit:    2,
+	}
+	names := func(users []User) []string {
+		var result []string
+		for _, u := range users {
+			result = append(result, u.Name)
+		}
+		return result
+	}
+
+	// age/id order: Diana(20,4) Alice(30,1) Charlie(30,3) Bob(40,2) Eve(40,5)
+	var users []User
+	ep.Model = &users
+	page1, err := dao.PaginateCursor(ctx, ep)
+	s.Require().NoError(err)
+	s.Equal([]string{"Diana", "Alice"}, names(users))
+	s.NotEmpty(page1.Next)
+	s.Empty(page1.Prev)
+
+	ep.Cursor = page1.Next
+	page2, err := dao.PaginateCursor(ctx, ep)
+	s.Require().NoError(err)
+	s.Equal([]string{"Charlie", "Bob"}, names(users))
+	s.NotEmpty(page2.Next)
+	s.NotEmpty(page2.Prev)
+
+	ep.Cursor = page2.Next
+	page3, err := dao.PaginateCursor(ctx, ep)
+	s.Require().NoError(err)
+	s.Equal([]string{"Eve"}, names(users))
+	s.Empty(page3.Next)
+	s.NotEmpty(page3.Prev)
+
+	ep.Cursor = page3.Prev
+	back, err := dao.PaginateCursor(ctx, ep)
+	s.Require().NoError(err)
+	s.Equal([]string{"Charlie", "Bob"}, names(users))
+	s.NotEmpty(back.Next)
+
+	ep.Cursor = back.Prev
+	first, err := dao.PaginateCursor(ctx, ep)
+	s.Require().NoError(err)
+	s.Equal([]string{"Diana", "Alice"}, names(users))
+	s.Empty(first.Prev)
+
+	// A cursor signed with another key is rejected.
+	ep.Cursor = page1.Next
+	_, err = NewDAO[User](s.db, WithCursorSecret([]byte("other"))).PaginateCursor(ctx, ep)
+	s.ErrorIs(err, ErrInvalidCursor)
+
+	// A cursor issued for other conditions is rejected, whether the clause or its arguments differ.
+	ep.Conditions = map[string]any{"age": Gte(20)}
+	_, err = dao.PaginateCursor(ctx, ep)
+	s.ErrorIs(err, ErrInvalidCursor)
+	ep.Cursor = ""
+	filtered, err := dao.PaginateCursor(ctx, ep)
+	s.Require().NoError(err)
+	ep.Cursor = filtered.Next
+	_, err = dao.PaginateCursor(ctx, ep)
+	s.Require().NoError(err)
+	ep.Conditions = map[string]any{"age": Gte(30)}
+	_, err = dao.PaginateCursor(ctx, ep)
+	s.ErrorIs(err, ErrInvalidCursor)
+	ep.Conditions = map[string]any{"age": Lte(20)}
+	_, err = dao.PaginateCursor(ctx, ep)
+	s.ErrorIs(err, ErrInvalidCursor)
+	ep.Conditions = nil
+
+	// Sort keys must map to fields of T.
+	ep.Cursor = ""
+	ep.SortKeys = []SortKey{{Column: "missing"}}
+	_, err = dao.PaginateCursor(ctx, ep)
+	s.Error(err)
+}
+
+func (s *DAOTestSuite) TestHooks() {
+	ctx := context.Background()
+	var (
+		order  []string
+		events []QueryEvent
+	)
+	recorder := HookFuncs{
+		Before: func(ctx context.Context, e *QueryEvent) (context.Context, error) {
+			order = append(order, "before1")
+			return ctx, nil
+		},
+		After: func(ctx context.Context, e *QueryEvent) {
+			order = append(order, "after1")
+			events = append(events, *e)
+		},
+	}
+	second := HookFuncs{
+		Before: func(ctx context.Context, e *QueryEvent) (context.Context, error) {
+			order = append(order, "before2")
+			return ctx, nil
+		},
+		After: func(ctx context.Context, e *QueryEvent) {
+			order = append(order, "after2")
+		},
+	}
+	dao := NewDAO[User](s.db, WithHooks(recorder, second))
+
+	var users []User
+	err := dao.Select(ctx, SelectEndPoint[User]{Model: &users, Table: "users"})
+	s.Require().NoError(err)
+	s.Equal([]string{"before1", "before2", "after2", "after1"}, order)
+	s.Require().Len(events, 1)
+	s.Equal("Select", events[0].Operation)
+	s.Equal("users", events[0].Table)
+	s.Equal("SELECT `id`,`name`,`age` FROM `users`", events[0].SQL)
+	s.Equal(int64(2), events[0].RowsAffected)
+	s.NoError(events[0].Err)
+
+	affected, err := dao.Update(ctx, UpdateEndPoint[User]{
+		Table:      "users",
+		Rows:       map[string]any{"age": 31},
+		Conditions: map[string]any{"id": Eq(1)},
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+	s.Equal("Update", events[1].Operation)
+	s.Equal([]any{31, 1}, events[1].Args)
+	s.Equal(int64(1), events[1].RowsAffected)
+
+	// 失败的语句也会触发 AfterQuery
+	var user User
+	err = dao.Get(ctx, GetEndPoint[User]{Model: &user, Table: "users", Conditions: map[string]any{"id": Eq(99)}})
+	s.ErrorIs(err, sql.ErrNoRows)
+	s.Equal("Get", events[2].Operation)
+	s.ErrorIs(events[2].Err, sql.ErrNoRows)
+
+	// Paginate 执行两条语句
+	events = nil
+	_, err = dao.Paginate(ctx, PageEndPoint[User]{Model: &users, Table: "users", PageNo: 1, PageSize: 1})
+	s.Require().NoError(err)
+	s.Require().Len(events, 2)
+	s.Equal(int64(1), events[1].RowsAffected)
+}
+
+func (s *DAOTestSuite) TestHooks_BeforeQueryAborts() {
+	ctx := context.Background()
+	errBlocked := errors.New("blocked")
+	var after []QueryEvent
+	dao := NewDAO[User](s.db,
+		WithHooks(HookFuncs{After: func(ctx context.Context, e *QueryEvent) { after = append(after, *e) }}),
+		WithHooks(HookFuncs{Before: func(ctx context.Context, e *QueryEvent) (context.Context, error) {
+			if e.Operation == "Delete" {
+				return ctx, errBlocked
+			}
+			return ctx, nil
+		}}),
+	)
+
+	_, err := dao.Delete(ctx, DeleteEndPoint[User]{Table: "users", Conditions: map[string]any{"id": Eq(1)}})
+	s.ErrorIs(err, errBlocked)
+	s.Require().Len(after, 1)
+	s.ErrorIs(after[0].Err, errBlocked)
+	s.Equal(int64(-1), after[0].RowsAffected)
+
+	var count int
+	s.Require().NoError(s.db.Get(&count, "SELECT COUNT(*) FROM users"))
+	s.Equal(2, count, "delete must not run")
+}
+
+func (s *DAOTestSuite) TestHooks_NilContext() {
+	// BeforeQuery 返回 nil context 时沿用之前的 context
+	var got context.Context
+	dao := NewDAO[User](s.db, WithHooks(
+		HookFuncs{Before: func(context.Context, *QueryEvent) (context.Context, error) { return nil, nil }},
+		HookFuncs{
+			Before: func(ctx context.Context, e *QueryEvent) (context.Context, error) { return ctx, nil },
+			After:  func(ctx context.Context, e *QueryEvent) { got = ctx },
+		},
+	))
+	var users []User
+	s.Require().NoError(dao.Select(context.Background(), SelectEndPoint[User]{Model: &users}))
+	s.Len(users, 2)
+	s.NotNil(got)
+	for _, err := range dao.Iterate(context.Background(), SelectEndPoint[User]{}) {
+		s.Require().NoError(err)
+	}
+}
+
+func (s *DAOTestSuite) TestHooks_RewriteAndTx() {
+	ctx := context.Background()
+	type ctxKey struct{}
+	var seen []any
+	dao := NewDAO[User](s.db, WithHooks(HookFuncs{
+		Before: func(ctx context.Context, e *QueryEvent) (context.Context, error) {
+			e.SQL += " ORDER BY id DESC"
+			return context.WithValue(ctx, ctxKey{}, e.Operation), nil
+		},
+		After: func(ctx context.Context, e *QueryEvent) {
+			seen = append(seen, ctx.Value(ctxKey{}))
+		},
+	}))
+
+	txDAO, err := dao.BeginTx(ctx)
+	s.Require().NoError(err)
+	var users []User
+	s.Require().NoError(txDAO.Select(ctx, SelectEndPoint[User]{Model: &users, Table: "users"}))
+	s.Require().NoError(txDAO.Commit())
+
+	s.Equal([]any{"Select"}, seen)
+	s.Require().Len(users, 2)
+	s.Equal(int64(2), users[0].ID)
+}
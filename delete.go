@@ -5,22 +5,22 @@ import (
 	"fmt"
 )
 
-func (s DeleteEndPoint[T]) point2Sql() (string, []any, error) {
-	tableQuery, err := buildTableClause(s.Table)
+func (s DeleteEndPoint[T]) point2Sql(dialect Dialect) (string, []any, error) {
+	tableQuery, err := buildTableClause(dialect, s.Table)
 	if err != nil {
 		return "", nil, err
 	}
 
-	// For safety, DELETE must have conditions
-	if len(s.Conditions) == 0 {
-		return "", nil, errors.New("empty conditions for delete")
-	}
-
-	conditionsQuery, conditionsArgs, err := buildWhereClause(s.Conditions)
+	conditionsQuery, conditionsArgs, err := buildWhereClause(dialect, s.Conditions)
 	if err != nil {
 		return "", nil, err
 	}
 
+	// For safety, DELETE must have conditions
+	if conditionsQuery == "" {
+		return "", nil, errors.New("empty conditions for delete")
+	}
+
 	query := fmt.Sprintf("DELETE FROM %v %v", tableQuery, conditionsQuery)
 
 	return query, conditionsArgs, nil
@@ -0,0 +1,327 @@
+package db_dao
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect describes the SQL differences between databases.
+// Endpoints always build queries with `?` placeholders; the Dialect rebinds them
+// and renders the database-specific clauses.
+type Dialect interface {
+	// Name returns the dialect name, e.g. "postgres".
+	Name() string
+	// BindType returns the sqlx bindvar type (sqlx.QUESTION, sqlx.DOLLAR, sqlx.AT, ...).
+	BindType() int
+	// Rebind converts `?` placeholders into the dialect's placeholder style.
+	Rebind(query string) string
+	// Quote quotes an identifier; qualified names such as "u.name" are quoted per part.
+	Quote(identifier string) string
+	// LimitOffset renders the paging clause. A limit <= 0 means no limit.
+	// ordered reports whether the query already has an ORDER BY clause.
+	LimitOffset(limit, offset int64, ordered bool) string
+	// Upsert renders the conflict clause appended to an INSERT statement.
+	Upsert(columns, conflictColumns, updateColumns []string, doNothing bool) (string, error)
+	// SupportsReturning reports whether generated values are read back with the clause rendered
+	// by Returning instead of LastInsertId.
+	SupportsReturning() bool
+	// Returning adds the clause that returns columns to an INSERT ... VALUES statement,
+	// e.g. a trailing RETURNING or an OUTPUT INSERTED before VALUES.
+	Returning(insert string, columns []string) string
+	// BoolLiteral renders a boolean literal.
+	BoolLiteral(b bool) string
+	// SupportsRowValues reports whether row value comparisons such as (a, b) > (?, ?) are supported.
+	SupportsRowValues() bool
+	// MaxParams returns the maximum number of bind parameters in a single statement.
+	// BatchInsert splits its rows into chunks that stay within this limit.
+	MaxParams() int
+	// Savepoint renders the statement that creates a savepoint inside a transaction.
+	Savepoint(name string) string
+	// ReleaseSavepoint renders the statement that releases a savepoint,
+	// or "" when the database releases savepoints only with the transaction.
+	ReleaseSavepoint(name string) string
+	// RollbackToSavepoint renders the statement that undoes the work done since a savepoint.
+	RollbackToSavepoint(name string) string
+}
+
+var (
+	// Postgres is the PostgreSQL dialect ($1 placeholders, RETURNING, ON CONFLICT).
+	Postgres Dialect = postgresDialect{}
+	// MySQL is the MySQL/MariaDB dialect (? placeholders, LastInsertId, ON DUPLICATE KEY UPDATE).
+	MySQL Dialect = mysqlDialect{}
+	// SQLite is the SQLite dialect (? placeholders, LastInsertId, ON CONFLICT).
+	SQLite Dialect = sqliteDialect{}
+	// SQLServer is the Microsoft SQL Server dialect (@p1 placeholders, OUTPUT INSERTED, OFFSET ... FETCH NEXT).
+	SQLServer Dialect = sqlServerDialect{}
+)
+
+// ErrUnknownDialect is returned when a DAO executes a statement on an executor whose driver cannot be
+// detected (it implements neither DriverName() nor Unwrap() Executor, or DialectFor does not recognise
+// the driver name) and no WithDialect is given.
+var ErrUnknownDialect = errors.New("cannot detect the SQL dialect of the executor; use WithDialect")
+
+// undetected 是无法识别驱动时的方言：可以生成 SQL，但 DAO 不会执行，避免以错误的占位符访问数据库
+var undetected Dialect = undetectedDialect{}
+
+type undetectedDialect struct{ sqliteDialect }
+
+func (undetectedDialect) Name() string { return "unknown" }
+
+// DialectFor returns the dialect for a database/sql driver name.
+// Drivers it does not recognise (e.g. Oracle's godror/oci8 or clickhouse) get a dialect named "unknown":
+// it still renders SQL, but a DAO using it fails every statement with ErrUnknownDialect; use WithDialect instead.
+func DialectFor(driverName string) Dialect {
+	switch sqlx.BindType(driverName) {
+	case sqlx.DOLLAR:
+		return Postgres
+	case sqlx.AT:
+		return SQLServer
+	}
+	switch {
+	case strings.Contains(driverName, "mysql"):
+		return MySQL
+	case strings.Contains(driverName, "sqlserver"), strings.Contains(driverName, "mssql"):
+		return SQLServer
+	case strings.Contains(driverName, "sqlite"):
+		return SQLite
+	}
+	return undetected
+}
+
+// --- PostgreSQL ---
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string                   { return "postgres" }
+func (postgresDialect) BindType() int                  { return sqlx.DOLLAR }
+func (postgresDialect) Rebind(query string) string     { return sqlx.Rebind(sqlx.DOLLAR, query) }
+func (postgresDialect) Quote(identifier string) string { return quoteIdentifier(identifier, `"`, `"`) }
+func (postgresDialect) SupportsReturning() bool        { return true }
+func (postgresDialect) BoolLiteral(b bool) string      { return strings.ToUpper(fmt.Sprint(b)) }
+func (postgresDialect) SupportsRowValues() bool        { return true }
+func (postgresDialect) MaxParams() int                 { return 65535 }
+
+func (postgresDialect) Savepoint(name string) string        { return "SAVEPOINT " + name }
+func (postgresDialect) ReleaseSavepoint(name string) string { return "RELEASE SAVEPOINT " + name }
+func (postgresDialect) RollbackToSavepoint(name string) string {
+	return "ROLLBACK TO SAVEPOINT " + name
+}
+
+func (postgresDialect) LimitOffset(limit, offset int64, _ bool) string {
+	return limitOffset(limit, offset, "")
+}
+
+func (d postgresDialect) Returning(insert string, columns []string) string {
+	return insert + " " + buildReturningClause(d, columns)
+}
+
+func (d postgresDialect) Upsert(columns, conflictColumns, updateColumns []string, doNothing bool) (string, error) {
+	return onConflictClause(d, columns, conflictColumns, updateColumns, doNothing)
+}
+
+// --- MySQL ---
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string                   { return "mysql" }
+func (mysqlDialect) BindType() int                  { return sqlx.QUESTION }
+func (mysqlDialect) Rebind(query string) string     { return query }
+func (mysqlDialect) Quote(identifier string) string { return quoteIdentifier(identifier, "`", "`") }
+func (mysqlDialect) SupportsReturning() bool        { return false }
+func (mysqlDialect) BoolLiteral(b bool) string      { return boolDigit(b) }
+func (mysqlDialect) SupportsRowValues() bool        { return true }
+func (mysqlDialect) MaxParams() int                 { return 65535 }
+
+func (mysqlDialect) Savepoint(name string) string           { return "SAVEPOINT " + name }
+func (mysqlDialect) ReleaseSavepoint(name string) string    { return "RELEASE SAVEPOINT " + name }
+func (mysqlDialect) RollbackToSavepoint(name string) string { return "ROLLBACK TO SAVEPOINT " + name }
+
+func (mysqlDialect) LimitOffset(limit, offset int64, _ bool) string {
+	// MySQL 不支持单独的 OFFSET，使用文档推荐的最大值表示不限制
+	return limitOffset(limit, offset, "18446744073709551615")
+}
+
+func (mysqlDialect) Returning(insert string, _ []string) string { return insert }
+
+func (d mysqlDialect) Upsert(columns, conflictColumns, updateColumns []string, doNothing bool) (string, error) {
+	if !doNothing && len(updateColumns) == 0 {
+		updateColumns = excludeColumns(columns, conflictColumns)
+	}
+	if doNothing || len(updateColumns) == 0 {
+		// 将某一列更新为自身，等价于 DO NOTHING，且不会像 INSERT IGNORE 那样吞掉其它错误
+		return fmt.Sprintf("ON DUPLICATE KEY UPDATE %[1]v = %[1]v", d.Quote(columns[0])), nil
+	}
+	sets := make([]string, 0, len(updateColumns))
+	for _, c := range updateColumns {
+		sets = append(sets, fmt.Sprintf("%[1]v = VALUES(%[1]v)", d.Quote(c)))
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ","), nil
+}
+
+// --- SQLite ---
+
+// sqliteDialect 用反引号引用标识符：SQLite 会把找不到对应列的双引号名称当作字符串字面量，拼错的列名不会报错
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string                   { return "sqlite" }
+func (sqliteDialect) BindType() int                  { return sqlx.QUESTION }
+func (sqliteDialect) Rebind(query string) string     { return query }
+func (sqliteDialect) Quote(identifier string) string { return quoteIdentifier(identifier, "`", "`") }
+func (sqliteDialect) SupportsReturning() bool        { return false }
+func (sqliteDialect) BoolLiteral(b bool) string      { return boolDigit(b) }
+func (sqliteDialect) SupportsRowValues() bool        { return true }
+func (sqliteDialect) MaxParams() int                 { return 32766 }
+
+func (sqliteDialect) Savepoint(name string) string           { return "SAVEPOINT " + name }
+func (sqliteDialect) ReleaseSavepoint(name string) string    { return "RELEASE SAVEPOINT " + name }
+func (sqliteDialect) RollbackToSavepoint(name string) string { return "ROLLBACK TO SAVEPOINT " + name }
+
+func (sqliteDialect) LimitOffset(limit, offset int64, _ bool) string {
+	return limitOffset(limit, offset, "-1")
+}
+
+func (sqliteDialect) Returning(insert string, _ []string) string { return insert }
+
+func (d sqliteDialect) Upsert(columns, conflictColumns, updateColumns []string, doNothing bool) (string, error) {
+	return onConflictClause(d, columns, conflictColumns, updateColumns, doNothing)
+}
+
+// --- SQL Server ---
+
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) Name() string                   { return "sqlserver" }
+func (sqlServerDialect) BindType() int                  { return sqlx.AT }
+func (sqlServerDialect) Rebind(query string) string     { return sqlx.Rebind(sqlx.AT, query) }
+func (sqlServerDialect) Quote(identifier string) string { return quoteIdentifier(identifier, "[", "]") }
+func (sqlServerDialect) SupportsReturning() bool        { return true }
+func (sqlServerDialect) BoolLiteral(b bool) string      { return boolDigit(b) }
+func (sqlServerDialect) SupportsRowValues() bool        { return false }
+func (sqlServerDialect) MaxParams() int                 { return 2100 }
+
+func (sqlServerDialect) Savepoint(name string) string   { return "SAVE TRANSACTION " + name }
+func (sqlServerDialect) ReleaseSavepoint(string) string { return "" }
+func (sqlServerDialect) RollbackToSavepoint(name string) string {
+	return "ROLLBACK TRANSACTION " + name
+}
+
+func (sqlServerDialect) LimitOffset(limit, offset int64, ordered bool) string {
+	var b strings.Builder
+	// OFFSET ... FETCH 必须跟在 ORDER BY 之后
+	if !ordered {
+		b.WriteString("ORDER BY (SELECT NULL) ")
+	}
+	b.WriteString(fmt.Sprintf("OFFSET %d ROWS", offset))
+	if limit > 0 {
+		b.WriteString(fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", limit))
+	}
+	return b.String()
+}
+
+func (d sqlServerDialect) Returning(insert string, columns []string) string {
+	// OUTPUT 必须位于列清单与 VALUES 之间
+	i := strings.Index(insert, " VALUES ")
+	if i < 0 || len(columns) == 0 {
+		return insert
+	}
+	output := make([]string, len(columns))
+	for j, c := range columns {
+		output[j] = "INSERTED." + d.Quote(c)
+	}
+	return insert[:i] + " OUTPUT " + strings.Join(output, ",") + insert[i:]
+}
+
+func (sqlServerDialect) Upsert([]string, []string, []string, bool) (string, error) {
+	return "", errors.New("upsert is not supported by the sqlserver dialect")
+}
+
+// --- helpers ---
+
+// limitOffset 渲染 LIMIT/OFFSET 子句；noLimit 为仅有 OFFSET 时使用的 LIMIT 值，为空表示可省略 LIMIT。
+func limitOffset(limit, offset int64, noLimit string) string {
+	switch {
+	case limit > 0:
+		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+	case offset > 0 && noLimit != "":
+		return fmt.Sprintf("LIMIT %s OFFSET %d", noLimit, offset)
+	case offset > 0:
+		return fmt.Sprintf("OFFSET %d", offset)
+	}
+	return ""
+}
+
+// onConflictClause 渲染 Postgres/SQLite 的 ON CONFLICT 子句
+func onConflictClause(dialect Dialect, columns, conflictColumns, updateColumns []string, doNothing bool) (string, error) {
+	if !doNothing && len(updateColumns) == 0 {
+		updateColumns = excludeColumns(columns, conflictColumns)
+	}
+	if len(updateColumns) == 0 {
+		doNothing = true
+	}
+
+	var target string
+	if len(conflictColumns) > 0 {
+		target = fmt.Sprintf(" (%v)", strings.Join(quoteIdents(dialect, conflictColumns), ","))
+	}
+	if doNothing {
+		return fmt.Sprintf("ON CONFLICT%v DO NOTHING", target), nil
+	}
+	if target == "" {
+		return "", errors.New("empty conflict columns for upsert")
+	}
+	sets := make([]string, 0, len(updateColumns))
+	for _, c := range updateColumns {
+		sets = append(sets, fmt.Sprintf("%[1]v = EXCLUDED.%[1]v", dialect.Quote(c)))
+	}
+	return fmt.Sprintf("ON CONFLICT%v DO UPDATE SET %v", target, strings.Join(sets, ",")), nil
+}
+
+func quoteIdentifier(identifier, open, close string) string {
+	parts := strings.Split(identifier, ".")
+	for i, p := range parts {
+		if p == "*" {
+			continue
+		}
+		parts[i] = open + strings.ReplaceAll(p, close, close+close) + close
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteIdent 引用列名 (可带表名/别名前缀，如 u.name、u.*)；表达式和旧写法中带运算符的键原样返回
+func quoteIdent(dialect Dialect, name string) string {
+	if identifierPattern.MatchString(name) || identifierPattern.MatchString(strings.TrimSuffix(name, ".*")) {
+		return dialect.Quote(name)
+	}
+	return name
+}
+
+func quoteIdents(dialect Dialect, names []string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = quoteIdent(dialect, n)
+	}
+	return quoted
+}
+
+// quoteTable 引用 "表名"、"表名 别名" 或 "表名 AS 别名" 中的标识符，其它写法原样返回
+func quoteTable(dialect Dialect, table string) string {
+	if !joinTablePattern.MatchString(table) {
+		return table
+	}
+	f := strings.Fields(table)
+	f[0] = dialect.Quote(f[0])
+	if len(f) > 1 {
+		f[len(f)-1] = dialect.Quote(f[len(f)-1])
+	}
+	return strings.Join(f, " ")
+}
+
+func boolDigit(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
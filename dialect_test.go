@@ -0,0 +1,181 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- dialect_test.go: Tests for the SQL dialects ---
+
+func TestDialectFor(t *testing.T) {
+	assert.Equal(t, Postgres, DialectFor("pgx"))
+	assert.Equal(t, Postgres, DialectFor("postgres"))
+	assert.Equal(t, MySQL, DialectFor("mysql"))
+	assert.Equal(t, SQLite, DialectFor("sqlite3"))
+	assert.Equal(t, SQLServer, DialectFor("sqlserver"))
+	assert.Equal(t, SQLServer, DialectFor("mssql"))
+	assert.Equal(t, SQLite, DialectFor("sqlite"))
+	assert.Equal(t, undetected, DialectFor(""))
+	assert.Equal(t, undetected, DialectFor("godror"))
+	assert.Equal(t, undetected, DialectFor("clickhouse"))
+}
+
+func TestDialect_Rebind(t *testing.T) {
+	query := "SELECT * FROM users WHERE (id = ?) AND (age > ?)"
+	assert.Equal(t, "SELECT * FROM users WHERE (id = $1) AND (age > $2)", Postgres.Rebind(query))
+	assert.Equal(t, "SELECT * FROM users WHERE (id = @p1) AND (age > @p2)", SQLServer.Rebind(query))
+	assert.Equal(t, query, MySQL.Rebind(query))
+	assert.Equal(t, query, SQLite.Rebind(query))
+}
+
+func TestDialect_Quote(t *testing.T) {
+	assert.Equal(t, `"users"."name"`, Postgres.Quote("users.name"))
+	assert.Equal(t, "`name`", MySQL.Quote("name"))
+	assert.Equal(t, "`we\"ird`", SQLite.Quote(`we"ird`))
+	assert.Equal(t, "[u].*", SQLServer.Quote("u.*"))
+}
+
+func TestQuoteIdentifiersInStatements(t *testing.T) {
+	assert.Equal(t, "`u`.`name`", quoteIdent(MySQL, "u.name"))
+	assert.Equal(t, `"u".*`, quoteIdent(Postgres, "u.*"))
+	assert.Equal(t, "COUNT(*) AS n", quoteIdent(Postgres, "COUNT(*) AS n"))
+	assert.Equal(t, "age >", quoteIdent(Postgres, "age >"))
+	assert.Equal(t, `"users" AS "u"`, quoteTable(Postgres, "users AS u"))
+	assert.Equal(t, "[dbo].[users] [u]", quoteTable(SQLServer, "dbo.users u"))
+
+	query, args, err := SelectEndPoint[struct{}]{
+		Table:      "order",
+		Fields:     []string{"key", "group"},
+		Conditions: map[string]any{"desc": Eq("x")},
+	}.ToSQL(MySQL)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT `key`,`group` FROM `order` WHERE (`desc` = ?)", query)
+	assert.Equal(t, []any{"x"}, args)
+}
+
+func TestDialect_Returning(t *testing.T) {
+	insert := "INSERT INTO users (age,name) VALUES (?,?),(?,?)"
+	assert.Equal(t, insert+` RETURNING "id","created_at"`, Postgres.Returning(insert, []string{"id", "created_at"}))
+	assert.Equal(t, `INSERT INTO users (age,name) OUTPUT INSERTED.[id],INSERTED.[created_at] VALUES (?,?),(?,?)`, SQLServer.Returning(insert, []string{"id", "created_at"}))
+	assert.Equal(t, insert, MySQL.Returning(insert, []string{"id"}))
+	assert.Equal(t, insert, SQLite.Returning(insert, []string{"id"}))
+	assert.True(t, SQLServer.SupportsReturning())
+}
+
+func TestInsertReturning_SQLServerOutput(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	// 只记录生成的语句，不真正执行
+	var queries []string
+	errAbort := errors.New("abort")
+	capture := HookFuncs{Before: func(ctx context.Context, event *QueryEvent) (context.Context, error) {
+		queries = append(queries, event.SQL)
+		return ctx, errAbort
+	}}
+	dao := NewDAO[User](db, WithDialect(SQLServer), WithHooks(capture))
+	var user User
+	_, err := dao.InsertReturning(context.Background(), InsertEndpoint[User]{Table: "users", Rows: map[string]any{"name": "A", "age": 1}, Returning: []string{"id"}, Model: &user})
+	assert.ErrorIs(t, err, errAbort)
+	var users []User
+	_, err = dao.BatchInsertReturning(context.Background(), BatchInsertEndpoint[User]{Table: "users", Rows: []map[string]any{{"name": "A"}, {"name": "B"}}, Returning: []string{"id"}, Model: &users})
+	assert.ErrorIs(t, err, errAbort)
+	assert.Equal(t, []string{
+		"INSERT INTO [users] ([age],[name]) OUTPUT INSERTED.[id] VALUES (@p1,@p2)",
+		"INSERT INTO [users] ([name]) OUTPUT INSERTED.[id] VALUES (@p1),(@p2)",
+	}, queries)
+}
+
+func TestDialect_LimitOffset(t *testing.T) {
+	assert.Equal(t, "LIMIT 10 OFFSET 20", Postgres.LimitOffset(10, 20, false))
+	assert.Equal(t, "OFFSET 20", Postgres.LimitOffset(0, 20, false))
+	assert.Equal(t, "LIMIT 18446744073709551615 OFFSET 20", MySQL.LimitOffset(0, 20, false))
+	assert.Equal(t, "LIMIT -1 OFFSET 20", SQLite.LimitOffset(0, 20, false))
+	assert.Equal(t, "", SQLite.LimitOffset(0, 0, false))
+	assert.Equal(t, "OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", SQLServer.LimitOffset(10, 20, true))
+	assert.Equal(t, "ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", SQLServer.LimitOffset(10, 0, false))
+}
+
+func TestDialect_BoolLiteral(t *testing.T) {
+	assert.Equal(t, "TRUE", Postgres.BoolLiteral(true))
+	assert.Equal(t, "FALSE", Postgres.BoolLiteral(false))
+	assert.Equal(t, "1", MySQL.BoolLiteral(true))
+	assert.Equal(t, "0", SQLServer.BoolLiteral(false))
+}
+
+func TestDialect_Upsert(t *testing.T) {
+	_, err := SQLServer.Upsert([]string{"id"}, []string{"id"}, nil, false)
+	assert.Error(t, err)
+}
+
+func TestPageEndPoint_point2pageSql_SQLServer(t *testing.T) {
+	var users []struct{}
+	ep := PageEndPoint[struct{}]{
+		Model:     &users,
+		Table:     "users",
+		PageNo:    3,
+		PageSize:  10,
+		SortField: "id",
+	}
+	query, _, err := ep.point2pageSql(SQLServer)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM [users] ORDER BY id ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`, query)
+
+	ep.SortField = ""
+	query, _, err = ep.point2pageSql(SQLServer)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM [users] ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`, query)
+}
+
+// wrappedExecutor hides the *sqlx.DB so the driver name cannot be detected.
+type wrappedExecutor struct {
+	Executor
+}
+
+// unwrappingExecutor wraps an executor and exposes it through Unwrap.
+type unwrappingExecutor struct {
+	Executor
+}
+
+func (u unwrappingExecutor) Unwrap() Executor { return u.Executor }
+
+func TestNewDAO_Dialect(t *testing.T) {
+	db := newSQLiteDB(t, createUsersTable, `INSERT INTO users (id, name, age) VALUES (1, 'Alice', 30)`)
+
+	assert.Equal(t, SQLite, NewDAO[User](db).Dialect())
+	assert.Equal(t, Postgres, NewDAO[User](sqlx.NewDb(db.DB, "pgx")).Dialect())
+	assert.Equal(t, Postgres, NewDAO[User](unwrappingExecutor{unwrappingExecutor{sqlx.NewDb(db.DB, "pgx")}}).Dialect())
+
+	// 无法识别驱动时拒绝执行，而不是按 SQLite 的 ? 占位符访问数据库
+	unknown := NewDAO[User](wrappedExecutor{db})
+	assert.Equal(t, "unknown", unknown.Dialect().Name())
+	var executed bool
+	unknown = NewDAO[User](wrappedExecutor{db}, WithHooks(HookFuncs{After: func(context.Context, *QueryEvent) { executed = true }}))
+	_, err := unknown.FindByID(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrUnknownDialect)
+	_, err = unknown.Insert(context.Background(), InsertEndpoint[User]{Rows: map[string]any{"name": "Bob"}})
+	assert.ErrorIs(t, err, ErrUnknownDialect)
+	assert.False(t, executed)
+
+	// An explicit dialect is honoured even for wrapped executors (SQLite accepts $N placeholders).
+	dao := NewDAO[User](wrappedExecutor{db}, WithDialect(Postgres))
+	assert.Equal(t, Postgres, dao.Dialect())
+	var user User
+	err = dao.Get(context.Background(), GetEndPoint[User]{
+		Model:      &user,
+		Table:      "users",
+		Conditions: map[string]any{"id = ": 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", user.Name)
+
+	// Transactions inherit the configured dialect.
+	txDAO, err := NewDAO[User](db, WithDialect(MySQL)).BeginTx(context.Background())
+	require.NoError(t, err)
+	defer txDAO.Rollback()
+	assert.Equal(t, MySQL, txDAO.(*DAO[User]).Dialect())
+}
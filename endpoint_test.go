@@ -17,9 +17,9 @@ func TestGetEndPoint_point2Sql(t *testing.T) {
 			Table:      "users",
 			Conditions: map[string]any{"id = ": 1},
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Equal(t, "SELECT * FROM users WHERE (id = ?)", query)
+		assert.Equal(t, "SELECT * FROM `users` WHERE (id = ?)", query)
 		assert.Equal(t, []any{1}, args)
 	})
 
@@ -32,16 +32,16 @@ func TestGetEndPoint_point2Sql(t *testing.T) {
 			Conditions: map[string]any{"id = ": 1},
 			Appends:    []string{"LIMIT 1"},
 		}
-		query, _, err := ep.point2Sql()
+		query, _, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Contains(t, query, "SELECT id,name FROM users")
+		assert.Contains(t, query, "SELECT `id`,`name` FROM `users`")
 		assert.Contains(t, query, "LIMIT 1")
 	})
 
 	t.Run("empty table", func(t *testing.T) {
 		var u struct{}
 		ep := GetEndPoint[struct{}]{Model: &u, Table: ""}
-		_, _, err := ep.point2Sql()
+		_, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 	})
 }
@@ -53,9 +53,9 @@ func TestSelectEndPoint_point2Sql(t *testing.T) {
 			Model: &users,
 			Table: "users",
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Equal(t, "SELECT * FROM users", query)
+		assert.Equal(t, "SELECT * FROM `users`", query)
 		assert.Nil(t, args)
 	})
 }
@@ -63,13 +63,13 @@ func TestSelectEndPoint_point2Sql(t *testing.T) {
 func TestInsertEndpoint_point2Sql(t *testing.T) {
 	t.Run("empty table", func(t *testing.T) {
 		ep := InsertEndpoint[struct{}]{Table: "", Rows: map[string]any{"a": 1}}
-		_, _, err := ep.point2Sql()
+		_, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 	})
 
 	t.Run("empty rows", func(t *testing.T) {
 		ep := InsertEndpoint[struct{}]{Table: "users", Rows: nil}
-		_, _, err := ep.point2Sql()
+		_, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 	})
 
@@ -78,9 +78,9 @@ func TestInsertEndpoint_point2Sql(t *testing.T) {
 			Table: "users",
 			Rows:  map[string]any{"name": "Alice"},
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Contains(t, query, "INSERT INTO users")
+		assert.Contains(t, query, "INSERT INTO `users`")
 		assert.Contains(t, query, "name")
 		assert.Contains(t, args, "Alice")
 	})
@@ -90,9 +90,9 @@ func TestInsertEndpoint_point2Sql(t *testing.T) {
 			Table: "users",
 			Rows:  map[string]any{"name": "Alice", "age": 30},
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Equal(t, "INSERT INTO users (age,name) VALUES (?,?)", query)
+		assert.Equal(t, "INSERT INTO `users` (`age`,`name`) VALUES (?,?)", query)
 		assert.Equal(t, []any{30, "Alice"}, args)
 	})
 }
@@ -100,13 +100,13 @@ func TestInsertEndpoint_point2Sql(t *testing.T) {
 func TestBatchInsertEndpoint_point2Sql(t *testing.T) {
 	t.Run("empty table", func(t *testing.T) {
 		ep := BatchInsertEndpoint[struct{}]{Table: "", Rows: []map[string]any{{"a": 1}}}
-		_, _, err := ep.point2Sql()
+		_, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 	})
 
 	t.Run("empty rows", func(t *testing.T) {
 		ep := BatchInsertEndpoint[struct{}]{Table: "users", Rows: nil}
-		_, _, err := ep.point2Sql()
+		_, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 	})
 
@@ -117,9 +117,9 @@ func TestBatchInsertEndpoint_point2Sql(t *testing.T) {
 				{"name": "Alice", "age": 30},
 			},
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Contains(t, query, "INSERT INTO users")
+		assert.Contains(t, query, "INSERT INTO `users`")
 		assert.Len(t, args, 2)
 	})
 
@@ -131,9 +131,9 @@ func TestBatchInsertEndpoint_point2Sql(t *testing.T) {
 				{"name": "Bob", "age": 40},
 			},
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Contains(t, query, "INSERT INTO users")
+		assert.Contains(t, query, "INSERT INTO `users`")
 		assert.Len(t, args, 4)
 		// Should have two value groups
 		assert.Contains(t, query, "VALUES")
@@ -147,9 +147,9 @@ func TestBatchInsertEndpoint_point2Sql(t *testing.T) {
 				{"age": 40, "name": "Bob"},
 			},
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Equal(t, "INSERT INTO users (age,name) VALUES (?,?),(?,?)", query)
+		assert.Equal(t, "INSERT INTO `users` (`age`,`name`) VALUES (?,?),(?,?)", query)
 		assert.Equal(t, []any{30, "Alice", 40, "Bob"}, args)
 	})
 
@@ -161,7 +161,7 @@ func TestBatchInsertEndpoint_point2Sql(t *testing.T) {
 				{"name": "Bob"},
 			},
 		}
-		_, _, err := ep.point2Sql()
+		_, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 		assert.Equal(t, "rows transfer failed", err.Error())
 	})
@@ -173,7 +173,7 @@ func TestUpdateEndPoint_point2Sql(t *testing.T) {
 			Table: "users",
 			Rows:  map[string]any{"age": 31},
 		}
-		_, _, _, err := ep.point2Sql()
+		_, _, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 		assert.Equal(t, "empty conditions for update", err.Error())
 	})
@@ -183,7 +183,7 @@ func TestUpdateEndPoint_point2Sql(t *testing.T) {
 			Table:      "users",
 			Conditions: map[string]any{"id = ": 1},
 		}
-		_, _, _, err := ep.point2Sql()
+		_, _, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 	})
 
@@ -193,9 +193,9 @@ func TestUpdateEndPoint_point2Sql(t *testing.T) {
 			Rows:       map[string]any{"age": 31},
 			Conditions: map[string]any{"id = ": 1},
 		}
-		query, rowsArgs, conditionsArgs, err := ep.point2Sql()
+		query, rowsArgs, conditionsArgs, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Contains(t, query, "UPDATE users SET age = ?")
+		assert.Contains(t, query, "UPDATE `users` SET `age` = ?")
 		assert.Contains(t, query, "WHERE (id = ?)")
 		assert.Equal(t, []any{31}, rowsArgs)
 		assert.Equal(t, []any{1}, conditionsArgs)
@@ -205,7 +205,7 @@ func TestUpdateEndPoint_point2Sql(t *testing.T) {
 func TestDeleteEndPoint_point2Sql(t *testing.T) {
 	t.Run("empty conditions", func(t *testing.T) {
 		ep := DeleteEndPoint[struct{}]{Table: "users"}
-		_, _, err := ep.point2Sql()
+		_, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 		assert.Equal(t, "empty conditions for delete", err.Error())
 	})
@@ -215,7 +215,7 @@ func TestDeleteEndPoint_point2Sql(t *testing.T) {
 			Table:      "",
 			Conditions: map[string]any{"id = ": 1},
 		}
-		_, _, err := ep.point2Sql()
+		_, _, err := ep.point2Sql(SQLite)
 		assert.Error(t, err)
 	})
 
@@ -224,9 +224,9 @@ func TestDeleteEndPoint_point2Sql(t *testing.T) {
 			Table:      "users",
 			Conditions: map[string]any{"id = ": 1},
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Equal(t, "DELETE FROM users WHERE (id = ?)", query)
+		assert.Equal(t, "DELETE FROM `users` WHERE (id = ?)", query)
 		assert.Equal(t, []any{1}, args)
 	})
 }
@@ -238,9 +238,9 @@ func TestPageEndPoint_point2Sql(t *testing.T) {
 			Model: &users,
 			Table: "users",
 		}
-		query, _, err := ep.point2Sql()
+		query, _, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Equal(t, "SELECT COUNT(*) FROM users ", query)
+		assert.Equal(t, "SELECT COUNT(*) FROM `users` ", query)
 	})
 
 	t.Run("count query with conditions", func(t *testing.T) {
@@ -250,9 +250,9 @@ func TestPageEndPoint_point2Sql(t *testing.T) {
 			Table:      "users",
 			Conditions: map[string]any{"age > ": 18},
 		}
-		query, args, err := ep.point2Sql()
+		query, args, err := ep.point2Sql(SQLite)
 		require.NoError(t, err)
-		assert.Contains(t, query, "SELECT COUNT(*) FROM users WHERE")
+		assert.Contains(t, query, "SELECT COUNT(*) FROM `users` WHERE")
 		assert.Equal(t, []any{18}, args)
 	})
 }
@@ -266,9 +266,9 @@ func TestPageEndPoint_point2pageSql(t *testing.T) {
 			PageNo:   1,
 			PageSize: 10,
 		}
-		query, _, err := ep.point2pageSql()
+		query, _, err := ep.point2pageSql(SQLite)
 		require.NoError(t, err)
-		assert.Contains(t, query, "SELECT * FROM users")
+		assert.Contains(t, query, "SELECT * FROM `users`")
 		assert.Contains(t, query, "LIMIT 10 OFFSET 0")
 	})
 
@@ -280,7 +280,7 @@ func TestPageEndPoint_point2pageSql(t *testing.T) {
 			PageNo:   2,
 			PageSize: 10,
 		}
-		query, _, err := ep.point2pageSql()
+		query, _, err := ep.point2pageSql(SQLite)
 		require.NoError(t, err)
 		assert.Contains(t, query, "LIMIT 10 OFFSET 10")
 	})
@@ -295,7 +295,7 @@ func TestPageEndPoint_point2pageSql(t *testing.T) {
 			SortField: "created_at",
 			SortOrder: "DESC",
 		}
-		query, _, err := ep.point2pageSql()
+		query, _, err := ep.point2pageSql(SQLite)
 		require.NoError(t, err)
 		assert.Contains(t, query, "ORDER BY created_at DESC")
 	})
@@ -309,11 +309,30 @@ func TestPageEndPoint_point2pageSql(t *testing.T) {
 			PageSize:  10,
 			SortField: "id",
 		}
-		query, _, err := ep.point2pageSql()
+		query, _, err := ep.point2pageSql(SQLite)
 		require.NoError(t, err)
 		assert.Contains(t, query, "ORDER BY id ASC")
 	})
 
+	t.Run("multiple sort keys override SortField", func(t *testing.T) {
+		var users []struct{}
+		ep := PageEndPoint[struct{}]{
+			Model:     &users,
+			Table:     "users",
+			PageNo:    2,
+			PageSize:  10,
+			SortField: "ignored",
+			SortKeys:  []SortKey{{Column: "created_at", Desc: true}, {Column: "id"}},
+		}
+		query, _, err := ep.point2pageSql(SQLite)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM `users` ORDER BY `created_at` DESC, `id` ASC LIMIT 10 OFFSET 10", query)
+
+		ep.SortKeys = []SortKey{{Column: "id; DROP TABLE users"}}
+		_, _, err = ep.point2pageSql(SQLite)
+		assert.Error(t, err)
+	})
+
 	t.Run("invalid pageNo = 0", func(t *testing.T) {
 		var users []struct{}
 		ep := PageEndPoint[struct{}]{
@@ -322,7 +341,7 @@ func TestPageEndPoint_point2pageSql(t *testing.T) {
 			PageNo:   0,
 			PageSize: 10,
 		}
-		_, _, err := ep.point2pageSql()
+		_, _, err := ep.point2pageSql(SQLite)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "pageNo")
 	})
@@ -335,7 +354,7 @@ func TestPageEndPoint_point2pageSql(t *testing.T) {
 			PageNo:   -1,
 			PageSize: 10,
 		}
-		_, _, err := ep.point2pageSql()
+		_, _, err := ep.point2pageSql(SQLite)
 		assert.Error(t, err)
 	})
 
@@ -347,8 +366,154 @@ func TestPageEndPoint_point2pageSql(t *testing.T) {
 			PageNo:   1,
 			PageSize: 0,
 		}
-		_, _, err := ep.point2pageSql()
+		_, _, err := ep.point2pageSql(SQLite)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "pageSize")
 	})
 }
+
+func TestUpsertEndpoint_point2Sql(t *testing.T) {
+	t.Run("on conflict update all non-conflict columns", func(t *testing.T) {
+		ep := UpsertEndpoint[struct{}]{
+			Table:           "users",
+			Rows:            map[string]any{"id": 1, "name": "Alice", "age": 30},
+			ConflictColumns: []string{"id"},
+		}
+		query, args, err := ep.point2Sql(SQLite)
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO `users` (`age`,`id`,`name`) VALUES (?,?,?) ON CONFLICT (`id`) DO UPDATE SET `age` = EXCLUDED.`age`,`name` = EXCLUDED.`name`", query)
+		assert.Equal(t, []any{30, 1, "Alice"}, args)
+	})
+
+	t.Run("explicit update columns", func(t *testing.T) {
+		ep := UpsertEndpoint[struct{}]{
+			Table:           "users",
+			Rows:            map[string]any{"id": 1, "name": "Alice", "age": 30},
+			ConflictColumns: []string{"id"},
+			UpdateColumns:   []string{"name"},
+		}
+		query, _, err := ep.point2Sql(Postgres)
+		require.NoError(t, err)
+		assert.Equal(t, `INSERT INTO "users" ("age","id","name") VALUES (?,?,?) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`, query)
+	})
+
+	t.Run("do nothing", func(t *testing.T) {
+		ep := UpsertEndpoint[struct{}]{
+			Table:     "users",
+			Rows:      map[string]any{"id": 1},
+			DoNothing: true,
+		}
+		query, _, err := ep.point2Sql(SQLite)
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO `users` (`id`) VALUES (?) ON CONFLICT DO NOTHING", query)
+	})
+
+	t.Run("update requires conflict columns", func(t *testing.T) {
+		ep := UpsertEndpoint[struct{}]{
+			Table: "users",
+			Rows:  map[string]any{"id": 1, "name": "Alice"},
+		}
+		_, _, err := ep.point2Sql(SQLite)
+		assert.EqualError(t, err, "empty conflict columns for upsert")
+	})
+
+	t.Run("mysql on duplicate key", func(t *testing.T) {
+		ep := UpsertEndpoint[struct{}]{
+			Table:           "users",
+			Rows:            map[string]any{"id": 1, "name": "Alice", "age": 30},
+			ConflictColumns: []string{"id"},
+		}
+		query, _, err := ep.point2Sql(MySQL)
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO `users` (`age`,`id`,`name`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `age` = VALUES(`age`),`name` = VALUES(`name`)", query)
+	})
+
+	t.Run("mysql do nothing", func(t *testing.T) {
+		ep := UpsertEndpoint[struct{}]{
+			Table:     "users",
+			Rows:      map[string]any{"id": 1, "name": "Alice"},
+			DoNothing: true,
+		}
+		query, _, err := ep.point2Sql(MySQL)
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO `users` (`id`,`name`) VALUES (?,?) ON DUPLICATE KEY UPDATE `id` = `id`", query)
+	})
+
+	t.Run("empty rows", func(t *testing.T) {
+		ep := UpsertEndpoint[struct{}]{Table: "users", ConflictColumns: []string{"id"}}
+		_, _, err := ep.point2Sql(SQLite)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid conflict or update columns", func(t *testing.T) {
+		ep := UpsertEndpoint[struct{}]{
+			Table:           "users",
+			Rows:            map[string]any{"id": 1, "name": "Alice"},
+			ConflictColumns: []string{"id) DO NOTHING; DROP TABLE users --"},
+		}
+		_, _, err := ep.point2Sql(SQLite)
+		assert.ErrorContains(t, err, "invalid column name")
+
+		ep.ConflictColumns = []string{"id"}
+		ep.UpdateColumns = []string{"name = 'x', is_admin"}
+		_, _, err = ep.point2Sql(MySQL)
+		assert.ErrorContains(t, err, "invalid column name")
+	})
+}
+
+func TestBatchUpsertEndpoint_point2Sql(t *testing.T) {
+	ep := BatchUpsertEndpoint[struct{}]{
+		Table: "users",
+		Rows: []map[string]any{
+			{"id": 1, "name": "Alice"},
+			{"id": 2, "name": "Bob"},
+		},
+		ConflictColumns: []string{"id"},
+	}
+	query, args, err := ep.point2Sql(Postgres)
+	require.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "users" ("id","name") VALUES (?,?),(?,?) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`, query)
+	assert.Equal(t, []any{1, "Alice", 2, "Bob"}, args)
+
+	_, _, err = BatchUpsertEndpoint[struct{}]{Table: "users"}.point2Sql(Postgres)
+	assert.Error(t, err)
+
+	ep.UpdateColumns = []string{"name = EXCLUDED.name, role"}
+	_, _, err = ep.point2Sql(Postgres)
+	assert.ErrorContains(t, err, "invalid column name")
+}
+
+func TestEndpoints_ConditionTree(t *testing.T) {
+	cond := AnyOf{map[string]any{"id": Eq(1)}, Not{Condition: map[string]any{"age": Lt(18)}}}
+
+	t.Run("delete", func(t *testing.T) {
+		query, args, err := DeleteEndPoint[struct{}]{Table: "users", Conditions: cond}.point2Sql(SQLite)
+		require.NoError(t, err)
+		assert.Equal(t, "DELETE FROM `users` WHERE ((`id` = ?)) OR (NOT ((`age` < ?)))", query)
+		assert.Equal(t, []any{1, 18}, args)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		query, _, conditionsArgs, err := UpdateEndPoint[struct{}]{
+			Table:      "users",
+			Rows:       map[string]any{"age": 1},
+			Conditions: cond,
+		}.point2Sql(SQLite)
+		require.NoError(t, err)
+		assert.Equal(t, "UPDATE `users` SET `age` = ? WHERE ((`id` = ?)) OR (NOT ((`age` < ?)))", query)
+		assert.Equal(t, []any{1, 18}, conditionsArgs)
+	})
+
+	t.Run("empty tree is rejected for update and delete", func(t *testing.T) {
+		_, _, err := DeleteEndPoint[struct{}]{Table: "users", Conditions: And{}}.point2Sql(SQLite)
+		assert.EqualError(t, err, "empty conditions for delete")
+		_, _, _, err = UpdateEndPoint[struct{}]{Table: "users", Rows: map[string]any{"a": 1}, Conditions: Or{}}.point2Sql(SQLite)
+		assert.EqualError(t, err, "empty conditions for update")
+	})
+
+	t.Run("page count", func(t *testing.T) {
+		query, _, err := PageEndPoint[struct{}]{Table: "users", Conditions: cond}.point2Sql(SQLite)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT COUNT(*) FROM `users` WHERE ((`id` = ?)) OR (NOT ((`age` < ?)))", query)
+	})
+}
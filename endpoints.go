@@ -4,57 +4,152 @@ package db_dao
 type GetEndPoint[T any] struct {
 	Model      *T
 	Table      string
-	Conditions map[string]any
+	Joins      []Join // Joins 连接的表，ON 条件与 Conditions 写法相同
+	Conditions Condition
 	Appends    []string
 	Fields     []string
+
+	compiled *compiled // compiled 由 QueryBuilder 设置，严格模式下放行其生成的 Appends
 }
 
 // SelectEndPoint Select选择器
 type SelectEndPoint[T any] struct {
 	Model      *[]T
 	Table      string
-	Conditions map[string]any
+	Joins      []Join // Joins 连接的表，ON 条件与 Conditions 写法相同
+	Conditions Condition
 	Appends    []string
 	Fields     []string
+
+	compiled *compiled // compiled 由 QueryBuilder 设置，严格模式下放行其生成的 Appends
 }
 
 // PageEndPoint Select分页选择器
 type PageEndPoint[T any] struct {
 	Model      *[]T
 	Table      string
-	Conditions map[string]any
-	SortField  string // SortField 用于指定排序字段
-	SortOrder  string // SortOrder 用于指定排序顺序 (ASC/DESC)
+	Joins      []Join // Joins 连接的表，ON 条件与 Conditions 写法相同
+	Conditions Condition
+	SortField  string    // SortField 用于指定排序字段
+	SortOrder  string    // SortOrder 用于指定排序顺序 (ASC/DESC)
+	SortKeys   []SortKey // SortKeys 按多列排序，设置后忽略 SortField / SortOrder
 	PageNo     int32
 	PageSize   int32
 	Fields     []string
 }
 
+// CursorPageEndPoint 游标 (keyset) 分页选择器
+type CursorPageEndPoint[T any] struct {
+	Model      *[]T
+	Table      string
+	Joins      []Join // Joins 连接的表，ON 条件与 Conditions 写法相同
+	Conditions Condition
+	SortKeys   []SortKey // SortKeys 排序键，组合起来必须唯一 (通常以主键结尾)，且需映射到 T 的 db 字段
+	Limit      int32
+	Cursor     string // Cursor 上一次返回的 CursorPage.Next 或 CursorPage.Prev，为空表示第一页
+	Fields     []string
+}
+
+// SortKey 游标分页的排序键
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// CursorPage 游标分页结果
+type CursorPage struct {
+	Next string // Next 下一页游标，没有更多数据时为空
+	Prev string // Prev 上一页游标，没有更早的数据时为空
+}
+
 // UpdateEndPoint Update选择器
 type UpdateEndPoint[T any] struct {
 	Table      string
 	Rows       map[string]any
-	Conditions map[string]any
+	Conditions Condition
 	Appends    []string
 }
 
 // InsertEndpoint Insert选择器
 type InsertEndpoint[T any] struct {
-	Table string
-	Rows  map[string]any
+	Table     string
+	Rows      map[string]any
+	Returning []string // Returning 指定需要回填的生成列 (仅 InsertReturning 使用)
+	Model     *T       // Model 接收 Returning 列的值 (仅 InsertReturning 使用)
 }
 
 // BatchInsertEndpoint BatchInsert选择器
 type BatchInsertEndpoint[T any] struct {
-	Table string
-	Rows  []map[string]any
+	Table     string
+	Rows      []map[string]any
+	Returning []string // Returning 指定需要回填的生成列 (仅 BatchInsertReturning 使用)
+	Model     *[]T     // Model 按 Rows 的顺序接收 Returning 列的值 (仅 BatchInsertReturning 使用)
+	ChunkSize int      // ChunkSize 每条 INSERT 的最大行数，0 表示按方言的参数上限推算 (BatchInsert / BatchInsertReturning 使用)
+	Atomic    bool     // Atomic 分批时在同一事务中执行，任一批失败则全部回滚 (仅 BatchInsert 使用)
+}
+
+// UpsertEndpoint Upsert选择器 (INSERT ... ON CONFLICT / ON DUPLICATE KEY UPDATE)
+type UpsertEndpoint[T any] struct {
+	Table           string
+	Rows            map[string]any
+	ConflictColumns []string // ConflictColumns 冲突目标列 (MySQL 忽略，由唯一索引决定)
+	UpdateColumns   []string // UpdateColumns 冲突时更新的列，为空时更新除冲突列外的所有列
+	DoNothing       bool     // DoNothing 冲突时不做任何更新
+}
+
+// BatchUpsertEndpoint BatchUpsert选择器
+type BatchUpsertEndpoint[T any] struct {
+	Table           string
+	Rows            []map[string]any
+	ConflictColumns []string
+	UpdateColumns   []string
+	DoNothing       bool
 }
 
 // DeleteEndPoint Delete选择器
 type DeleteEndPoint[T any] struct {
 	Table      string
-	Conditions map[string]any
+	Conditions Condition
+}
+
+// AggregateEndPoint 聚合查询选择器 (GROUP BY / HAVING)
+type AggregateEndPoint[T any] struct {
+	Table      string
+	Joins      []Join
+	Conditions Condition
+	Aggregates []Aggregation // Aggregates 聚合列，如 db_dao.Sum("amount").As("total")
+	GroupBy    []string      // GroupBy 分组列，同时作为结果列查询
+	Having     Condition     // Having 分组过滤，写法与 Conditions 相同，键可以是聚合列的别名
+	SortKeys   []SortKey     // SortKeys 排序，列可以是分组列或聚合列的别名
+	Limit      int32         // Limit 最多返回的分组数，0 表示不限制
 }
 
+// Condition 是 WHERE 条件，可以是以下任意一种，并可任意嵌套：
+//   - map[string]any：各键以 AND 连接，按键排序保证 SQL 稳定
+//   - And / AnyOf / Or / Not：布尔条件节点
+//   - nil：无条件
+type Condition = any
+
+// And is a slice of conditions that should be AND-ed together, in order.
+type And []Condition
+
+// AnyOf is a slice of conditions that should be OR-ed together, in order.
+// Unlike Or, its elements may be any Condition, including nested And / AnyOf / Not nodes.
+type AnyOf []Condition
+
 // Or is a slice of conditions that should be OR-ed together.
 type Or []map[string]any
+
+// conditions 将 Or 转换为等价的 AnyOf
+func (o Or) conditions() AnyOf {
+	out := make(AnyOf, len(o))
+	for i, m := range o {
+		out[i] = m
+	}
+	return out
+}
+
+// Not negates a condition.
+type Not struct {
+	Condition Condition
+}
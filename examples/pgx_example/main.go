@@ -57,24 +57,19 @@ func main() {
 
 	// 5. Insert a user
 	fmt.Println("\n--- Inserting User ---")
-	// Note: For Postgres SERIAL, we usually let DB handle ID, but InsertEndpoint supports returning ID if needed.
-	// However, the base Insert method in dao.go uses Exec, which returns LastInsertId.
-	// IMPORTANT: lib/pq and pgx do NOT support LastInsertId() for standard Exec calls.
-	// To get the ID back in Postgres, we usually need "RETURNING id" and QueryRow.
-	//
-	// Current DAO implementation uses `result.RowsAffected()` which works, but `result.LastInsertId()` will fail on PG.
-	// Let's see if we can use it.
-
-	insertData := db_dao.InsertEndpoint[User]{
-		Table: "users",
-		Rows:  map[string]any{"name": "PgUser", "age": 25},
-	}
-
-	rowsAffected, err := userDAO.Insert(ctx, insertData)
+	// Postgres does not support LastInsertId(), so InsertReturning appends
+	// "RETURNING id" and scans the generated key back into the model.
+	var inserted User
+	rowsAffected, err := userDAO.InsertReturning(ctx, db_dao.InsertEndpoint[User]{
+		Table:     "users",
+		Rows:      map[string]any{"name": "PgUser", "age": 25},
+		Returning: []string{"id"},
+		Model:     &inserted,
+	})
 	if err != nil {
 		log.Fatalf("Insert failed: %v", err)
 	}
-	fmt.Printf("Rows affected: %d\n", rowsAffected)
+	fmt.Printf("Rows affected: %d, new ID: %d\n", rowsAffected, inserted.ID)
 
 	// 6. Select users
 	fmt.Println("\n--- Selecting Users ---")
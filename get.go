@@ -5,15 +5,15 @@ import (
 	"strings"
 )
 
-func (s GetEndPoint[T]) point2Sql() (string, []any, error) {
-	fieldsQuery := buildFieldsClause(s.Fields)
+func (s GetEndPoint[T]) point2Sql(dialect Dialect) (string, []any, error) {
+	fieldsQuery := buildFieldsClause(dialect, s.Fields)
 
-	tableQuery, err := buildTableClause(s.Table)
+	tableQuery, joinArgs, err := buildFromClause(dialect, s.Table, s.Joins)
 	if err != nil {
 		return "", nil, err
 	}
 
-	conditionsQuery, conditionsArgs, err := buildWhereClause(s.Conditions)
+	conditionsQuery, conditionsArgs, err := buildWhereClause(dialect, s.Conditions)
 	if err != nil {
 		return "", nil, err
 	}
@@ -33,5 +33,5 @@ func (s GetEndPoint[T]) point2Sql() (string, []any, error) {
 		queryBuilder.WriteString(appendsQuery)
 	}
 
-	return queryBuilder.String(), conditionsArgs, nil
+	return queryBuilder.String(), append(joinArgs, conditionsArgs...), nil
 }
\ No newline at end of file
@@ -0,0 +1,161 @@
+package db_dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueryEvent describes a single SQL statement executed by a DAO.
+type QueryEvent struct {
+	Operation string // Operation is the DAO method name, e.g. "Select" or "Insert".
+	Table     string
+	SQL       string // SQL is the final statement, after placeholder rebinding.
+	Args      []any  // Args holds the bound values; values of sensitive columns are redacted wrappers.
+
+	// The fields below are only set when AfterQuery is called.
+	Duration     time.Duration
+	RowsAffected int64 // RowsAffected is the affected or returned row count, or -1 when unknown.
+	Err          error
+}
+
+// Hook observes or intercepts every statement a DAO executes.
+//
+// BeforeQuery runs before the statement; it may replace the context (e.g. to start a span),
+// modify event.SQL / event.Args, or return an error to abort the statement. A nil context keeps the current one.
+// AfterQuery runs afterwards with the duration, row count and error filled in; for streamed rows
+// (Iterate, ForEach) it runs once the rows are closed and covers the scan and iteration as well.
+// Hooks run in registration order before the query and in reverse order after it.
+type Hook interface {
+	BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error)
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// HookFuncs adapts plain functions to the Hook interface. Nil functions are skipped.
+type HookFuncs struct {
+	Before func(ctx context.Context, event *QueryEvent) (context.Context, error)
+	After  func(ctx context.Context, event *QueryEvent)
+}
+
+// BeforeQuery implements Hook.
+func (h HookFuncs) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	if h.Before == nil {
+		return ctx, nil
+	}
+	return h.Before(ctx, event)
+}
+
+// AfterQuery implements Hook.
+func (h HookFuncs) AfterQuery(ctx context.Context, event *QueryEvent) {
+	if h.After != nil {
+		h.After(ctx, event)
+	}
+}
+
+// WithHooks registers hooks that run around every statement executed by the DAO
+// and by the transactional DAOs derived from it.
+func WithHooks(hooks ...Hook) Option {
+	return func(c *config) {
+		c.hooks = append(c.hooks, hooks...)
+	}
+}
+
+// queryRun 记录一条语句的钩子状态，使 AfterQuery 可以推迟到结果集关闭之后调用
+type queryRun struct {
+	hooks []Hook
+	ctx   context.Context // ctx 最后一个 BeforeQuery 返回的 context
+	event *QueryEvent
+	ran   int // ran 已经执行过 BeforeQuery 的 hooks 数量
+	start time.Time
+}
+
+// beforeQuery 按注册顺序调用 BeforeQuery，方言未能识别时直接返回 ErrUnknownDialect；返回 nil context 的 hook 沿用之前的 context。
+// 某个 BeforeQuery 返回错误时，已经执行过 BeforeQuery 的 hooks 立即收到 AfterQuery，并返回该错误。
+func (c *config) beforeQuery(ctx context.Context, event *QueryEvent) (*queryRun, error) {
+	if c.dialect == undetected {
+		return nil, ErrUnknownDialect
+	}
+	run := &queryRun{hooks: c.hooks, ctx: ctx, event: event}
+	for _, h := range c.hooks {
+		hookCtx, err := h.BeforeQuery(run.ctx, event)
+		if err != nil {
+			run.after(-1, err)
+			return nil, err
+		}
+		if hookCtx != nil {
+			run.ctx = hookCtx
+		}
+		run.ran++
+	}
+	run.start = time.Now()
+	return run, nil
+}
+
+// after 填入耗时、行数与错误，并按相反顺序调用 AfterQuery
+func (r *queryRun) after(rows int64, err error) {
+	if !r.start.IsZero() {
+		r.event.Duration = time.Since(r.start)
+	}
+	r.event.RowsAffected = rows
+	r.event.Err = err
+	for i := r.ran - 1; i >= 0; i-- {
+		r.hooks[i].AfterQuery(r.ctx, r.event)
+	}
+}
+
+// runQuery 执行 fn 并在前后调用 hooks。fn 应使用 event.SQL / event.Args，以便 BeforeQuery 可以修改它们。
+// 某个 BeforeQuery 返回错误时，已经执行过 BeforeQuery 的 hooks 仍会收到 AfterQuery。
+func (c *config) runQuery(ctx context.Context, event *QueryEvent, fn func(context.Context, *QueryEvent) (int64, error)) error {
+	run, err := c.beforeQuery(ctx, event)
+	if err != nil {
+		return err
+	}
+	rows, err := fn(run.ctx, event)
+	run.after(rows, err)
+	return err
+}
+
+// hookedRows 是 queryxContext 返回的结果集。AfterQuery 在 Close 时才调用，
+// 因此 QueryEvent 的耗时、行数与错误包含遍历与扫描的过程。
+type hookedRows struct {
+	*sqlx.Rows
+	run    *queryRun
+	n      int64
+	err    error
+	closed bool
+}
+
+// Next 前进到下一行并计数
+func (r *hookedRows) Next() bool {
+	if !r.Rows.Next() {
+		return false
+	}
+	r.n++
+	return true
+}
+
+// fail 记录调用方在遍历中遇到的错误 (如扫描失败)，Close 时报告给 AfterQuery
+func (r *hookedRows) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Close 关闭结果集并调用 AfterQuery，重复调用时什么也不做
+func (r *hookedRows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	err := r.err
+	if err == nil {
+		err = r.Rows.Err()
+	}
+	closeErr := r.Rows.Close()
+	if err == nil {
+		err = closeErr
+	}
+	r.run.after(r.n, err)
+	return closeErr
+}
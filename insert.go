@@ -6,7 +6,7 @@ import (
 	"strings"
 )
 
-func (s InsertEndpoint[T]) point2Sql() (string, []any, error) {
+func (s InsertEndpoint[T]) point2Sql(dialect Dialect) (string, []any, error) {
 	var (
 		query       string
 		tableQuery  string
@@ -15,10 +15,10 @@ func (s InsertEndpoint[T]) point2Sql() (string, []any, error) {
 		rowsArgs    []any
 		err         error
 	)
-	if tableQuery, err = s.table2string(); err != nil {
+	if tableQuery, err = s.table2string(dialect); err != nil {
 		return query, rowsArgs, errors.New("table transfer failed")
 	}
-	if fieldsQuery, valuesQuery, rowsArgs, err = s.rows2sql(); err != nil {
+	if fieldsQuery, valuesQuery, rowsArgs, err = s.rows2sql(dialect); err != nil {
 		return query, rowsArgs, errors.New("rows transfer failed")
 	}
 	query = fmt.Sprintf("INSERT INTO %v %v VALUES %v", tableQuery, fieldsQuery, valuesQuery)
@@ -26,7 +26,7 @@ func (s InsertEndpoint[T]) point2Sql() (string, []any, error) {
 	return query, rowsArgs, err
 }
 
-func (s InsertEndpoint[T]) rows2sql() (string, string, []any, error) {
+func (s InsertEndpoint[T]) rows2sql(dialect Dialect) (string, string, []any, error) {
 	if len(s.Rows) == 0 {
 		return "", "", nil, errors.New("empty rows")
 	}
@@ -43,15 +43,15 @@ func (s InsertEndpoint[T]) rows2sql() (string, string, []any, error) {
 		prepareRows = append(prepareRows, "?")
 		args = append(args, v)
 	}
-	fieldsQuery = fmt.Sprintf("(%v)", strings.Join(prepareFields, ","))
+	fieldsQuery = fmt.Sprintf("(%v)", strings.Join(quoteIdents(dialect, prepareFields), ","))
 	valuesQuery = fmt.Sprintf("(%v)", strings.Join(prepareRows, ","))
 
 	return fieldsQuery, valuesQuery, args, nil
 }
 
-func (s InsertEndpoint[T]) table2string() (string, error) {
+func (s InsertEndpoint[T]) table2string(dialect Dialect) (string, error) {
 	if s.Table == "" {
 		return "", errors.New("empty table")
 	}
-	return s.Table, nil
+	return quoteTable(dialect, s.Table), nil
 }
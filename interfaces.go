@@ -3,6 +3,7 @@ package db_dao
 import (
 	"context"
 	"database/sql"
+	"iter"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -29,13 +30,34 @@ type Executor interface {
 type IDAO[T any] interface {
 	Get(context.Context, GetEndPoint[T]) error
 	Select(context.Context, SelectEndPoint[T]) error
+	Iterate(context.Context, SelectEndPoint[T]) iter.Seq2[T, error]
+	ForEach(ctx context.Context, endpoint SelectEndPoint[T], fn func(T) error) error
 	Paginate(context.Context, PageEndPoint[T]) (int64, error)
+	PaginateCursor(context.Context, CursorPageEndPoint[T]) (CursorPage, error)
+	Count(context.Context, AggregateEndPoint[T]) (int64, error)
+	Exists(context.Context, AggregateEndPoint[T]) (bool, error)
 	Insert(context.Context, InsertEndpoint[T]) (int64, error)
 	BatchInsert(context.Context, BatchInsertEndpoint[T]) (int64, error)
+	InsertReturning(context.Context, InsertEndpoint[T]) (int64, error)
+	BatchInsertReturning(context.Context, BatchInsertEndpoint[T]) (int64, error)
+	InsertModel(ctx context.Context, table string, model *T) (int64, error)
+	BatchInsertModels(ctx context.Context, table string, models []T) (int64, error)
+	Upsert(context.Context, UpsertEndpoint[T]) (int64, error)
+	BatchUpsert(context.Context, BatchUpsertEndpoint[T]) (int64, error)
 	Update(context.Context, UpdateEndPoint[T]) (int64, error)
 	Delete(context.Context, DeleteEndPoint[T]) (int64, error)
+	FindByID(ctx context.Context, id any) (*T, error)
+	FindByIDs(ctx context.Context, ids []any) (rows []T, missing []any, err error)
+	UpdateByID(ctx context.Context, id any, rows map[string]any) (int64, error)
+	DeleteByID(ctx context.Context, id any) (int64, error)
+	Query() *QueryBuilder[T]
+	Restore(context.Context, DeleteEndPoint[T]) (int64, error)
+	ForceDelete(context.Context, DeleteEndPoint[T]) (int64, error)
+	WithTrashed() IDAO[T]
+	OnlyTrashed() IDAO[T]
 	BeginTx(ctx context.Context, opts ...*sql.TxOptions) (IDAO[T], error)
 	Commit() error
 	Rollback() error
+	WithTx(ctx context.Context, opts *TxOptions, fn func(tx IDAO[T]) error) error
 	GetExecutor() Executor
 }
@@ -0,0 +1,93 @@
+package db_dao
+
+import (
+	"context"
+	"iter"
+	"reflect"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// Iterate streams the rows of a select query one at a time instead of loading them into a slice.
+// endpoint.Model is ignored. The rows are closed when the loop ends, including on break;
+// a query, scan or context error is yielded once as the last element:
+//
+//	for user, err := range userDAO.Iterate(ctx, db_dao.SelectEndPoint[User]{}) {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+func (d *DAO[T]) Iterate(ctx context.Context, endpoint SelectEndPoint[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		table, query, args, err := d.selectQuery(endpoint)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		rows, err := d.queryxContext(ctx, "Iterate", table, query, args)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		structScan := isStructScan(reflect.TypeOf((*T)(nil)).Elem())
+		for rows.Next() {
+			// 驱动只在读取下一批数据时检查 ctx，这里逐行检查以便及时停止
+			if err := ctx.Err(); err != nil {
+				rows.fail(err)
+				yield(zero, err)
+				return
+			}
+			var row T
+			if err := scanRow(rows.Rows, &row, structScan); err != nil {
+				rows.fail(err)
+				yield(zero, err)
+				return
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// ForEach calls fn for every row of a select query, streaming the rows like Iterate.
+// It stops at the first error, either from the query or returned by fn, and returns it.
+func (d *DAO[T]) ForEach(ctx context.Context, endpoint SelectEndPoint[T], fn func(T) error) error {
+	for row, err := range d.Iterate(ctx, endpoint) {
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isStructScan 判断 T 是否按列名映射到结构体字段；sql.Scanner 与基本类型按单列扫描，与 sqlx.Select 一致
+func isStructScan(t reflect.Type) bool {
+	t = reflectx.Deref(t)
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(scannerType)
+}
+
+// scanRow 将当前行扫描到 dest
+func scanRow[T any](rows *sqlx.Rows, dest *T, structScan bool) error {
+	if !structScan {
+		return rows.Scan(dest)
+	}
+	v := reflect.ValueOf(dest).Elem()
+	if v.Kind() == reflect.Pointer {
+		// *Struct 类型的行需要先分配
+		v.Set(reflect.New(v.Type().Elem()))
+		return rows.StructScan(v.Interface())
+	}
+	return rows.StructScan(dest)
+}
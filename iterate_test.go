@@ -0,0 +1,125 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- iterate_test.go: Tests for streaming iteration ---
+
+func (s *DAOTestSuite) TestIterate() {
+	ctx := context.Background()
+	var names []string
+	for user, err := range s.userDAO.Iterate(ctx, SelectEndPoint[User]{Appends: []string{"ORDER BY id"}}) {
+		s.Require().NoError(err)
+		names = append(names, user.Name)
+	}
+	s.Equal([]string{"Alice", "Bob"}, names)
+
+	// 非结构体类型按单列扫描
+	var ages []int
+	for age, err := range NewDAO[int](s.db).Iterate(ctx, SelectEndPoint[int]{Table: "users", Fields: []string{"age"}, Appends: []string{"ORDER BY id"}}) {
+		s.Require().NoError(err)
+		ages = append(ages, age)
+	}
+	s.Equal([]int{30, 40}, ages)
+
+	var errs []error
+	for _, err := range s.userDAO.Iterate(ctx, SelectEndPoint[User]{Table: "missing"}) {
+		errs = append(errs, err)
+	}
+	s.Require().Len(errs, 1)
+	s.Error(errs[0])
+}
+
+func (s *DAOTestSuite) TestForEach() {
+	ctx := context.Background()
+	var total int
+	err := s.userDAO.ForEach(ctx, SelectEndPoint[User]{}, func(u User) error {
+		total += u.Age
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Equal(70, total)
+
+	stop := errors.New("stop")
+	calls := 0
+	err = s.userDAO.ForEach(ctx, SelectEndPoint[User]{}, func(User) error {
+		calls++
+		return stop
+	})
+	s.ErrorIs(err, stop)
+	s.Equal(1, calls)
+}
+
+func (s *DAOTestSuite) TestIterate_Hooks() {
+	var events []QueryEvent
+	dao := NewDAO[User](s.db, WithHooks(HookFuncs{After: func(ctx context.Context, e *QueryEvent) { events = append(events, *e) }}))
+
+	// AfterQuery 在结果集关闭后才调用，行数为实际读取的行数
+	for _, err := range dao.Iterate(context.Background(), SelectEndPoint[User]{}) {
+		s.Require().NoError(err)
+		s.Empty(events)
+	}
+	s.Require().Len(events, 1)
+	s.Equal("Iterate", events[0].Operation)
+	s.Equal(int64(2), events[0].RowsAffected)
+	s.NoError(events[0].Err)
+
+	for range dao.Iterate(context.Background(), SelectEndPoint[User]{}) {
+		break
+	}
+	s.Require().Len(events, 2)
+	s.Equal(int64(1), events[1].RowsAffected)
+
+	// 遍历中的错误同样报告给 AfterQuery
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, err := range dao.Iterate(ctx, SelectEndPoint[User]{}) {
+		if err != nil {
+			break
+		}
+		cancel()
+	}
+	s.Require().Len(events, 3)
+	s.ErrorIs(events[2].Err, context.Canceled)
+}
+
+func TestIterate_ReleasesConnection(t *testing.T) {
+	// 只有一个连接：如果提前退出时没有关闭 rows，后续查询会一直等待连接
+	db := newSQLiteDB(t, createUsersTable, `INSERT INTO users (id, name, age) VALUES (1, 'Alice', 30), (2, 'Bob', 40), (3, 'Carol', 50)`)
+	dao := NewDAO[User](db)
+
+	for user, err := range dao.Iterate(context.Background(), SelectEndPoint[User]{}) {
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", user.Name)
+		break
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	user, err := dao.FindByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", user.Name)
+
+	// 迭代途中取消 ctx，最后一个元素是 ctx 的错误
+	ctx, cancelIter := context.WithCancel(context.Background())
+	defer cancelIter()
+	var seen int
+	var last error
+	for _, err := range dao.Iterate(ctx, SelectEndPoint[User]{}) {
+		if err != nil {
+			last = err
+			break
+		}
+		seen++
+		cancelIter()
+	}
+	assert.Equal(t, 1, seen)
+	assert.ErrorIs(t, last, context.Canceled)
+}
@@ -0,0 +1,93 @@
+package db_dao
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// JoinType 连接类型
+type JoinType string
+
+const (
+	InnerJoin JoinType = "INNER JOIN"
+	LeftJoin  JoinType = "LEFT JOIN"
+	RightJoin JoinType = "RIGHT JOIN"
+)
+
+// Join 连接子句，用于 GetEndPoint / SelectEndPoint / PageEndPoint / CursorPageEndPoint 的 Joins：
+//
+//	Table: "users u",
+//	Joins: []db_dao.Join{{
+//		Type:  db_dao.LeftJoin,
+//		Table: "orders o",
+//		On:    map[string]any{"o.user_id": db_dao.EqCol("u.id"), "o.status": db_dao.Eq("paid")},
+//	}},
+type Join struct {
+	Type  JoinType  // Type 为空时为 INNER JOIN
+	Table string    // Table 表名，可带别名，如 "orders o" 或 "orders AS o"
+	On    Condition // On 连接条件，写法与 Conditions 相同；列之间的比较使用 EqCol
+}
+
+// joinTablePattern 匹配 "表名"、"表名 别名" 或 "表名 AS 别名"
+var joinTablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?(\s+((?i:AS)\s+)?[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// buildFromClause 构建 FROM 子句及其连接，返回 ON 条件的参数
+func buildFromClause(dialect Dialect, table string, joins []Join) (string, []any, error) {
+	tableQuery, err := buildTableClause(dialect, table)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(joins) == 0 {
+		return tableQuery, nil, nil
+	}
+	var (
+		b    strings.Builder
+		args []any
+	)
+	b.WriteString(tableQuery)
+	for _, j := range joins {
+		joinType := j.Type
+		switch joinType {
+		case "":
+			joinType = InnerJoin
+		case InnerJoin, LeftJoin, RightJoin:
+		default:
+			return "", nil, fmt.Errorf("unsupported join type %q", j.Type)
+		}
+		if !joinTablePattern.MatchString(j.Table) {
+			return "", nil, fmt.Errorf("invalid join table %q", j.Table)
+		}
+		onQuery, onArgs, err := buildCondition(dialect, j.On)
+		if err != nil {
+			return "", nil, err
+		}
+		// 没有 ON 条件的连接几乎总是错误 (笛卡尔积)
+		if onQuery == "" {
+			return "", nil, fmt.Errorf("empty on condition for join %q", j.Table)
+		}
+		fmt.Fprintf(&b, " %s %s ON %s", joinType, quoteTable(dialect, j.Table), onQuery)
+		args = append(args, onArgs...)
+	}
+	return b.String(), args, nil
+}
+
+// tableQualifier 有连接时返回主表的别名 (没有别名时为表名)，用于限定默认字段和软删除列；没有连接时为空
+func tableQualifier(table string, joins []Join) string {
+	if len(joins) == 0 {
+		return ""
+	}
+	f := strings.Fields(table)
+	if len(f) == 0 {
+		return ""
+	}
+	return f[len(f)-1]
+}
+
+// qualify 为列名加上 qualifier 前缀
+func qualify(qualifier, column string) string {
+	if qualifier == "" {
+		return column
+	}
+	return qualifier + "." + column
+}
@@ -0,0 +1,134 @@
+package db_dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- join_test.go: Tests for join clauses ---
+
+func TestBuildFromClause(t *testing.T) {
+	query, args, err := buildFromClause(SQLite, "users u", []Join{
+		{Table: "orders o", On: map[string]any{"o.user_id": EqCol("u.id")}},
+		{Type: LeftJoin, Table: "refunds AS r", On: map[string]any{"r.order_id": EqCol("o.id"), "r.status": Eq("done")}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "`users` `u` INNER JOIN `orders` `o` ON (`o`.`user_id` = `u`.`id`) LEFT JOIN `refunds` AS `r` ON (`r`.`order_id` = `o`.`id`) AND (`r`.`status` = ?)", query)
+	assert.Equal(t, []any{"done"}, args)
+
+	query, args, err = buildFromClause(SQLite, "users", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "`users`", query)
+	assert.Nil(t, args)
+
+	_, _, err = buildFromClause(SQLite, "users", []Join{{Table: "orders o; DROP TABLE users", On: map[string]any{"o.user_id": EqCol("users.id")}}})
+	assert.EqualError(t, err, `invalid join table "orders o; DROP TABLE users"`)
+	_, _, err = buildFromClause(SQLite, "users", []Join{{Type: "CROSS JOIN", Table: "orders", On: map[string]any{"orders.user_id": EqCol("users.id")}}})
+	assert.EqualError(t, err, `unsupported join type "CROSS JOIN"`)
+	_, _, err = buildFromClause(SQLite, "users", []Join{{Table: "orders"}})
+	assert.EqualError(t, err, `empty on condition for join "orders"`)
+	_, _, err = buildFromClause(SQLite, "users", []Join{{Table: "orders", On: map[string]any{"orders.user_id": EqCol("users.id OR 1=1")}}})
+	assert.Error(t, err)
+}
+
+func TestJoinEndpoints(t *testing.T) {
+	joins := []Join{{Type: LeftJoin, Table: "orders o", On: map[string]any{"o.user_id": EqCol("u.id"), "o.status": Eq("paid")}}}
+
+	ep := PageEndPoint[struct{}]{
+		Table:      "users u",
+		Joins:      joins,
+		Conditions: map[string]any{"u.age": Gt(18)},
+		SortKeys:   []SortKey{{Column: "u.id"}},
+		PageNo:     1,
+		PageSize:   10,
+		Fields:     []string{"u.name", "o.total"},
+	}
+	query, args, err := ep.ToCountSQL(Postgres)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(*) FROM "users" "u" LEFT JOIN "orders" "o" ON ("o"."status" = $1) AND ("o"."user_id" = "u"."id") WHERE ("u"."age" > $2)`, query)
+	assert.Equal(t, []any{"paid", 18}, args)
+
+	query, args, err = ep.ToSQL(Postgres)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "u"."name","o"."total" FROM "users" "u" LEFT JOIN "orders" "o" ON ("o"."status" = $1) AND ("o"."user_id" = "u"."id") WHERE ("u"."age" > $2) ORDER BY "u"."id" ASC LIMIT 10 OFFSET 0`, query)
+	assert.Equal(t, []any{"paid", 18}, args)
+
+	query, args, err = CursorPageEndPoint[struct{}]{
+		Table:    "users u",
+		Joins:    joins,
+		SortKeys: []SortKey{{Column: "u.id"}},
+		Limit:    5,
+		Fields:   []string{"u.id"},
+	}.ToSQL(SQLite)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT `u`.`id` FROM `users` `u` LEFT JOIN `orders` `o` ON (`o`.`status` = ?) AND (`o`.`user_id` = `u`.`id`) ORDER BY `u`.`id` ASC LIMIT 6 OFFSET 0", query)
+	assert.Equal(t, []any{"paid"}, args)
+
+	// 有连接时默认字段与软删除列以主表别名限定
+	dao := NewDAO[User](nil, WithSoftDelete("deleted_at"))
+	assert.Equal(t, []string{"u.id", "u.name", "u.age"}, dao.fields(nil, tableQualifier("users u", joins)))
+	assert.Equal(t, []string{"users.id", "users.name", "users.age"}, dao.fields(nil, tableQualifier("users", joins)))
+	assert.Equal(t, []string{"id", "name", "age"}, dao.fields(nil, tableQualifier("users u", nil)))
+	scoped, err := dao.scoped(nil, "u")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"u.deleted_at": IsNull()}, scoped)
+
+	// 连接中的列同样受列名校验
+	strict := NewDAO[User](nil, WithColumnValidation("o.user_id"))
+	err = strict.checkColumns(columnRefs{joins: []Join{{Table: "orders o", On: map[string]any{"o.user_id": EqCol("u.secret")}}}})
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+	assert.NoError(t, strict.checkColumns(columnRefs{joins: []Join{{Table: "orders o", On: map[string]any{"o.user_id": EqCol("u.id")}}}}))
+}
+
+type userOrder struct {
+	Name  string `db:"name"`
+	Total int    `db:"total"`
+}
+
+func (s *DAOTestSuite) TestJoin() {
+	ctx := context.Background()
+	_, err := s.db.Exec(`DROP TABLE IF EXISTS orders`)
+	s.Require().NoError(err)
+	_, err = s.db.Exec(`CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER, total INTEGER, status TEXT)`)
+	s.Require().NoError(err)
+	_, err = s.db.Exec(`INSERT INTO orders (id, user_id, total, status) VALUES (1, 1, 100, 'paid'), (2, 1, 50, 'open'), (3, 2, 70, 'paid')`)
+	s.Require().NoError(err)
+
+	dao := NewDAO[userOrder](s.db)
+	var rows []userOrder
+	total, err := dao.Paginate(ctx, PageEndPoint[userOrder]{
+		Model:      &rows,
+		Table:      "users u",
+		Joins:      []Join{{Table: "orders o", On: map[string]any{"o.user_id": EqCol("u.id"), "o.status": Eq("paid")}}},
+		Conditions: map[string]any{"u.age": Gte(30)},
+		SortKeys:   []SortKey{{Column: "o.total", Desc: true}},
+		PageNo:     1,
+		PageSize:   1,
+		Fields:     []string{"u.name", "o.total"},
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(2), total)
+	s.Equal([]userOrder{{Name: "Alice", Total: 100}}, rows)
+
+	// LEFT JOIN 保留没有匹配订单的用户；默认字段以 u. 限定，不会与 orders.id 冲突
+	_, err = s.db.Exec(`INSERT INTO users (id, name, age) VALUES (3, 'Carol', 20)`)
+	s.Require().NoError(err)
+	var users []User
+	err = s.userDAO.Query().
+		Table("users u").
+		LeftJoin("orders o", map[string]any{"o.user_id": EqCol("u.id")}).
+		Where(map[string]any{"o.id": IsNull()}).
+		Select(ctx, &users)
+	s.Require().NoError(err)
+	s.Equal([]User{{ID: 3, Name: "Carol", Age: 20}}, users)
+
+	count, err := s.userDAO.Query().Table("users u").Join("orders o", map[string]any{"o.user_id": EqCol("u.id")}).Count(ctx)
+	s.Require().NoError(err)
+	s.Equal(int64(3), count)
+
+	_, err = s.userDAO.Query().Table("users u").Join("orders o", map[string]any{"o.user_id": EqCol("u.id")}).ToDelete()
+	s.EqualError(err, "joins are not supported for delete")
+}
@@ -0,0 +1,280 @@
+package db_dao
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redactedText replaces the value of a sensitive argument in logs.
+const redactedText = "[REDACTED]"
+
+// QueryLogger is a Hook that logs every statement with log/slog.
+//
+// Successful statements are logged at Level, statements slower than SlowThreshold at WARN
+// and failed statements at ERROR. Arguments bound to sensitive columns (see WithSensitiveColumns
+// and the `dao:"sensitive"` tag) are logged as "[REDACTED]".
+//
+//	dao := db_dao.NewDAO[User](db, db_dao.WithHooks(&db_dao.QueryLogger{
+//		Logger:        slog.Default(),
+//		Level:         slog.LevelDebug,
+//		SlowThreshold: 200 * time.Millisecond,
+//	}))
+type QueryLogger struct {
+	Logger        *slog.Logger  // Logger defaults to slog.Default().
+	Level         slog.Level    // Level is used for statements that are neither slow nor failed.
+	SlowThreshold time.Duration // SlowThreshold disables slow-query detection when zero.
+}
+
+// BeforeQuery implements Hook.
+func (l *QueryLogger) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterQuery implements Hook.
+func (l *QueryLogger) AfterQuery(ctx context.Context, event *QueryEvent) {
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	level, msg := l.Level, "query"
+	switch {
+	case event.Err != nil:
+		level, msg = slog.LevelError, "query failed"
+	case l.SlowThreshold > 0 && event.Duration >= l.SlowThreshold:
+		level, msg = slog.LevelWarn, "slow query"
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", event.Operation),
+		slog.String("table", event.Table),
+		slog.String("sql", event.SQL),
+		slog.Any("args", logArgs(event.Args)),
+		slog.Duration("duration", event.Duration),
+		slog.Int64("rows", event.RowsAffected),
+	}
+	if event.Err != nil {
+		attrs = append(attrs, slog.Any("error", event.Err))
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// logArgs 返回用于日志输出的参数副本，敏感参数替换为 redactedText
+func logArgs(args []any) []any {
+	out := make([]any, len(args))
+	for i, a := range args {
+		if _, ok := a.(redacted); ok {
+			out[i] = redactedText
+			continue
+		}
+		out[i] = a
+	}
+	return out
+}
+
+// WithSensitiveColumns marks columns of table whose values must never appear in QueryEvent.Args
+// as plain values, in addition to the fields of T tagged `dao:"sensitive"`.
+func WithSensitiveColumns(table string, columns ...string) Option {
+	return func(c *config) {
+		if c.sensitive == nil {
+			c.sensitive = make(map[string]map[string]bool)
+		}
+		if c.sensitive[table] == nil {
+			c.sensitive[table] = make(map[string]bool)
+		}
+		for _, column := range columns {
+			c.sensitive[table][column] = true
+		}
+	}
+}
+
+// redacted 包装敏感列的参数值。hooks 看到的是包装后的值，执行前由 unredact 还原。
+type redacted struct {
+	value any
+}
+
+// String keeps the value out of fmt-based logging in user hooks.
+func (redacted) String() string { return redactedText }
+
+// LogValue keeps the value out of slog-based logging in user hooks.
+func (redacted) LogValue() slog.Value { return slog.StringValue(redactedText) }
+
+// unredact 还原被包装的参数，未包含敏感参数时直接返回原切片
+func unredact(args []any) []any {
+	var out []any
+	for i, a := range args {
+		r, ok := a.(redacted)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make([]any, len(args))
+			copy(out, args)
+		}
+		out[i] = r.value
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+// sensitiveSet 返回 table 上需要脱敏的列，没有时返回 nil
+func (d *DAO[T]) sensitiveSet(table string) map[string]bool {
+	tagged := d.meta().tagged["sensitive"]
+	configured := d.cfg.sensitive[baseTable(table)]
+	if len(tagged) == 0 && len(configured) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(tagged)+len(configured))
+	for _, c := range tagged {
+		set[c] = true
+	}
+	for c := range configured {
+		set[c] = true
+	}
+	return set
+}
+
+// baseTable 去掉表别名，如 "users u" -> "users"
+func baseTable(table string) string {
+	if f := strings.Fields(table); len(f) > 0 {
+		return f[0]
+	}
+	return table
+}
+
+// keyColumn 从条件或行的键中取出列名，如 "u.email LIKE" / "email=" -> "email"。
+// 键先按 legacyKeyPattern 解析，不符合时退回取第一个单词
+func keyColumn(key string) string {
+	column := key
+	if m := legacyKeyPattern.FindStringSubmatch(key); m != nil {
+		column = m[1]
+	} else if f := strings.Fields(key); len(f) > 0 {
+		column = f[0]
+	} else {
+		return ""
+	}
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+	return column
+}
+
+// sensitiveKey 判断键是否引用敏感列。无法按 legacyKeyPattern 解析的键 (如 "LOWER(email) = ")
+// 可能以任意方式引用敏感列，一律视为敏感
+func sensitiveKey(set map[string]bool, key string) bool {
+	if !legacyKeyPattern.MatchString(key) {
+		return true
+	}
+	return set[keyColumn(key)]
+}
+
+// redactValue 包装一个条件或行的值，保持 nil / IN 列表 / Operator 的语义不变
+func redactValue(v any) any {
+	switch x := v.(type) {
+	case nil, redacted, rawExpr, columnRef:
+		return v
+	case Operator:
+		args := make([]any, 0, len(x.args))
+		for _, a := range x.args {
+			args = append(args, redactValue(a))
+		}
+		return Operator{op: x.op, args: args}
+	}
+	if isListValue(v) {
+		rv := reflect.ValueOf(v)
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = redacted{rv.Index(i).Interface()}
+		}
+		return list
+	}
+	return redacted{v}
+}
+
+// redactRowValue 包装 Insert / Update SET 中的值。行的值总是单个参数，不展开为 IN 列表
+func redactRowValue(v any) any {
+	switch v.(type) {
+	case nil, redacted, rawExpr:
+		return v
+	}
+	return redacted{v}
+}
+
+// redactRow 返回 row 的副本，其中敏感列的值被包装
+func redactRow(set map[string]bool, row map[string]any) map[string]any {
+	if len(set) == 0 || row == nil {
+		return row
+	}
+	out := make(map[string]any, len(row))
+	for k, v := range row {
+		if sensitiveKey(set, k) {
+			v = redactRowValue(v)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// redactRows 对每一行调用 redactRow
+func redactRows(set map[string]bool, rows []map[string]any) []map[string]any {
+	if len(set) == 0 {
+		return rows
+	}
+	out := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		out[i] = redactRow(set, row)
+	}
+	return out
+}
+
+// redactCondition 返回条件树的副本，其中敏感列的值被包装
+func redactCondition(set map[string]bool, condition Condition) Condition {
+	if len(set) == 0 {
+		return condition
+	}
+	switch c := condition.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(c))
+		for k, v := range c {
+			switch v.(type) {
+			case And, AnyOf, Or, Not:
+				v = redactCondition(set, v)
+			default:
+				if sensitiveKey(set, k) {
+					v = redactValue(v)
+				}
+			}
+			out[k] = v
+		}
+		return out
+	case And:
+		out := make(And, len(c))
+		for i, sub := range c {
+			out[i] = redactCondition(set, sub)
+		}
+		return out
+	case AnyOf:
+		out := make(AnyOf, len(c))
+		for i, sub := range c {
+			out[i] = redactCondition(set, sub)
+		}
+		return out
+	case Or:
+		out := make(Or, len(c))
+		for i, sub := range c {
+			out[i] = redactCondition(set, sub).(map[string]any)
+		}
+		return out
+	case Not:
+		return Not{redactCondition(set, c.Condition)}
+	}
+	return condition
+}
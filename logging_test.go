@@ -0,0 +1,168 @@
+package db_dao
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- logging_test.go: Tests for QueryLogger and argument redaction ---
+
+type account struct {
+	ID       int64  `db:"id"`
+	Email    string `db:"email" dao:"sensitive"`
+	Password string `db:"password"`
+	Name     string `db:"name"`
+}
+
+const createAccountsTable = `CREATE TABLE accounts (id INTEGER PRIMARY KEY, email TEXT, password TEXT, name TEXT)`
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestQueryLogger(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, createAccountsTable)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	dao := NewDAO[account](db,
+		WithHooks(&QueryLogger{Logger: logger, Level: slog.LevelDebug}),
+		WithSensitiveColumns("accounts", "password"),
+	)
+
+	_, err := dao.Insert(ctx, InsertEndpoint[account]{
+		Table: "accounts",
+		Rows:  map[string]any{"email": "alice@example.com", "password": "s3cret", "name": "Alice"},
+	})
+	require.NoError(t, err)
+
+	var got account
+	err = dao.Get(ctx, GetEndPoint[account]{
+		Model:      &got,
+		Table:      "accounts a",
+		Conditions: Or{map[string]any{"a.email = ": "alice@example.com"}, map[string]any{"name": In("x", "y")}},
+	})
+	require.NoError(t, err)
+	// 数据库中保存的是原始值
+	assert.Equal(t, "alice@example.com", got.Email)
+	assert.Equal(t, "s3cret", got.Password)
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, "DEBUG", lines[0]["level"])
+	assert.Equal(t, "query", lines[0]["msg"])
+	assert.Equal(t, "Insert", lines[0]["operation"])
+	assert.Equal(t, "accounts", lines[0]["table"])
+	assert.Equal(t, "INSERT INTO `accounts` (`email`,`name`,`password`) VALUES (?,?,?)", lines[0]["sql"])
+	assert.Equal(t, []any{redactedText, "Alice", redactedText}, lines[0]["args"])
+	assert.Equal(t, float64(1), lines[0]["rows"])
+
+	assert.Equal(t, "Get", lines[1]["operation"])
+	assert.Equal(t, []any{redactedText, "x", "y"}, lines[1]["args"])
+	assert.NotContains(t, buf.String(), "alice@example.com")
+	assert.NotContains(t, buf.String(), "s3cret")
+}
+
+func TestQueryLogger_Levels(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, createAccountsTable)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	dao := NewDAO[account](db, WithHooks(&QueryLogger{Logger: logger, Level: slog.LevelDebug, SlowThreshold: time.Nanosecond}))
+
+	var accounts []account
+	require.NoError(t, dao.Select(ctx, SelectEndPoint[account]{Model: &accounts, Table: "accounts"}))
+	err := dao.Select(ctx, SelectEndPoint[account]{Model: &accounts, Table: "missing"})
+	require.Error(t, err)
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "WARN", lines[0]["level"])
+	assert.Equal(t, "slow query", lines[0]["msg"])
+	assert.Equal(t, "ERROR", lines[1]["level"])
+	assert.Equal(t, "query failed", lines[1]["msg"])
+	assert.Contains(t, lines[1]["error"], "no such table")
+
+	// 未达到慢查询阈值的语句使用 Level，低于 handler 级别时不输出
+	buf.Reset()
+	dao = NewDAO[account](db, WithHooks(&QueryLogger{Logger: logger, Level: slog.LevelDebug, SlowThreshold: time.Hour}))
+	require.NoError(t, dao.Select(ctx, SelectEndPoint[account]{Model: &accounts, Table: "accounts"}))
+	assert.Empty(t, buf.String())
+}
+
+type credential struct {
+	ID     int64           `db:"id"`
+	Secret json.RawMessage `db:"secret" dao:"sensitive"`
+}
+
+func TestRedact_ByteSliceValues(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, `CREATE TABLE credentials (id INTEGER PRIMARY KEY, secret BLOB)`)
+	var events []QueryEvent
+	dao := NewDAO[credential](db, WithHooks(HookFuncs{After: func(_ context.Context, e *QueryEvent) { events = append(events, *e) }}))
+
+	// 命名的字节切片类型作为单个值绑定，而不是展开为列表
+	_, err := dao.Insert(ctx, InsertEndpoint[credential]{Rows: map[string]any{"id": 1, "secret": json.RawMessage(`{"k":1}`)}})
+	require.NoError(t, err)
+	_, err = dao.Update(ctx, UpdateEndPoint[credential]{
+		Rows:       map[string]any{"secret": json.RawMessage(`{"k":2}`)},
+		Conditions: map[string]any{"secret": Eq(json.RawMessage(`{"k":1}`))},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []any{redacted{json.RawMessage(`{"k":2}`)}, redacted{json.RawMessage(`{"k":1}`)}}, events[1].Args)
+
+	c, err := dao.FindByID(ctx, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"k":2}`, string(c.Secret))
+}
+
+func TestRedactCondition(t *testing.T) {
+	set := map[string]bool{"email": true}
+	cond := And{
+		map[string]any{"email": Eq("a@b.c"), "name = ": "x", "group": Not{map[string]any{"email": []string{"d", "e"}}}},
+	}
+	query, args, err := buildCondition(SQLite, redactCondition(set, cond))
+	require.NoError(t, err)
+	assert.Equal(t, "((`email` = ?) AND (NOT ((`email` IN (?, ?)))) AND (name = ?))", query)
+	assert.Equal(t, []any{redacted{"a@b.c"}, redacted{"d"}, redacted{"e"}, "x"}, args)
+	assert.Equal(t, []any{"a@b.c", "d", "e", "x"}, unredact(args))
+
+	// 原条件不被修改
+	assert.Equal(t, Eq("a@b.c"), cond[0].(map[string]any)["email"])
+
+	_, args, err = buildCondition(SQLite, redactCondition(set, AnyOf{Or{{"email = ": "f"}}, Not{map[string]any{"name": Eq("y")}}}))
+	require.NoError(t, err)
+	assert.Equal(t, []any{redacted{"f"}, "y"}, args)
+
+	// 没有空格的键按旧式条件键解析，无法解析的键一律脱敏
+	out := redactCondition(set, map[string]any{"u.email=": "g", "LOWER(email) = ": "h", "name<>": "z"}).(map[string]any)
+	assert.Equal(t, redacted{"g"}, out["u.email="])
+	assert.Equal(t, redacted{"h"}, out["LOWER(email) = "])
+	assert.Equal(t, "z", out["name<>"])
+}
+
+func TestKeyColumn(t *testing.T) {
+	assert.Equal(t, "email", keyColumn("email"))
+	assert.Equal(t, "email", keyColumn("email="))
+	assert.Equal(t, "email", keyColumn("u.email NOT LIKE"))
+	assert.Equal(t, "LOWER(email)", keyColumn("LOWER(email) = "))
+	assert.Equal(t, "", keyColumn("  "))
+}
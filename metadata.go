@@ -0,0 +1,170 @@
+package db_dao
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// Tabler is implemented by models that name their own table.
+// It takes precedence over the naming strategy when an endpoint leaves Table empty.
+type Tabler interface {
+	TableName() string
+}
+
+// NamingStrategy derives a table name from the Go type name of a model.
+type NamingStrategy func(typeName string) string
+
+// SnakeCase converts a type name to snake_case, e.g. "UserProfile" -> "user_profile".
+func SnakeCase(typeName string) string {
+	runes := []rune(typeName)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// 在单词边界处插入下划线，连续大写 (如 HTTPServer) 视为一个单词
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SnakeCasePlural converts a type name to plural snake_case, e.g. "UserProfile" -> "user_profiles".
+// It is the default naming strategy.
+func SnakeCasePlural(typeName string) string {
+	name := SnakeCase(typeName)
+	switch {
+	case name == "":
+		return ""
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
+
+// WithNamingStrategy sets how table names are derived for models that do not implement Tabler.
+func WithNamingStrategy(strategy NamingStrategy) Option {
+	return func(c *config) {
+		c.naming = strategy
+	}
+}
+
+// modelMeta 按类型缓存的模型元数据
+type modelMeta struct {
+	typeName    string              // typeName 去掉泛型参数的类型名，匿名类型为空
+	tableName   string              // tableName TableName() 的返回值，未实现 Tabler 时为空
+	fields      []modelField        // fields 映射到列的字段，T 不是结构体或没有 db 字段时为空
+	columns     []string            // columns 与 fields 顺序一致的列名
+	primaryKeys []string            // primaryKeys 标注 dao:"pk" 的列，没有时为 id 列 (如果存在)
+	tagged      map[string][]string // tagged dao 标签选项 -> 列
+	err         error               // err modelFields 的错误
+}
+
+type metaKey struct {
+	mapper *reflectx.Mapper
+	t      reflect.Type
+}
+
+// metaRegistry 缓存 metaKey -> *modelMeta。列名依赖 mapper，因此 mapper 也是键的一部分。
+var metaRegistry sync.Map
+
+// metaOf 返回 t 的元数据，首次访问时解析并缓存
+func metaOf(m *reflectx.Mapper, t reflect.Type) *modelMeta {
+	key := metaKey{mapper: m, t: t}
+	if meta, ok := metaRegistry.Load(key); ok {
+		return meta.(*modelMeta)
+	}
+	meta, _ := metaRegistry.LoadOrStore(key, buildMeta(m, t))
+	return meta.(*modelMeta)
+}
+
+func buildMeta(m *reflectx.Mapper, t reflect.Type) *modelMeta {
+	meta := &modelMeta{tagged: make(map[string][]string)}
+	if t == nil {
+		return meta
+	}
+	meta.typeName, _, _ = strings.Cut(reflectx.Deref(t).Name(), "[")
+	// *T 的方法集同时包含值接收者和指针接收者的 TableName
+	if tabler, ok := reflect.New(t).Interface().(Tabler); ok {
+		meta.tableName = tabler.TableName()
+	}
+
+	meta.fields, meta.err = modelFields(m, t)
+	for _, f := range meta.fields {
+		meta.columns = append(meta.columns, f.column)
+		for _, option := range f.daoOptions {
+			meta.tagged[option] = append(meta.tagged[option], f.column)
+		}
+	}
+	meta.primaryKeys = meta.tagged["pk"]
+	if len(meta.primaryKeys) == 0 && slices.Contains(meta.columns, "id") {
+		meta.primaryKeys = []string{"id"}
+	}
+	return meta
+}
+
+// taggedColumn 返回第一个标注了 dao 标签选项 option 的列，没有时返回空字符串
+func (m *modelMeta) taggedColumn(option string) string {
+	if columns := m.tagged[option]; len(columns) > 0 {
+		return columns[0]
+	}
+	return ""
+}
+
+// daoOptions 解析字段的 dao 标签，如 `dao:"pk,sensitive"`
+func daoOptions(field reflect.StructField) []string {
+	var options []string
+	for _, o := range strings.Split(field.Tag.Get("dao"), ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+// meta 返回 T 的元数据
+func (d *DAO[T]) meta() *modelMeta {
+	return metaOf(mapperOf(d.db), reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// table 返回 endpoint 的表名，为空时由 TableName() 或命名策略推导
+func (d *DAO[T]) table(table string) string {
+	if table != "" {
+		return table
+	}
+	meta := d.meta()
+	if meta.tableName != "" {
+		return meta.tableName
+	}
+	if meta.typeName == "" || d.cfg.naming == nil {
+		return ""
+	}
+	return d.cfg.naming(meta.typeName)
+}
+
+// fields 返回 endpoint 要查询的列，为空时使用 T 的所有列；有连接时以 qualifier 限定，避免与其它表的同名列冲突
+func (d *DAO[T]) fields(fields []string, qualifier string) []string {
+	if len(fields) > 0 {
+		return fields
+	}
+	columns := d.meta().columns
+	if qualifier == "" || len(columns) == 0 {
+		return columns
+	}
+	qualified := make([]string, len(columns))
+	for i, c := range columns {
+		qualified[i] = qualify(qualifier, c)
+	}
+	return qualified
+}
@@ -0,0 +1,100 @@
+package db_dao
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- metadata_test.go: Tests for the model metadata registry ---
+
+type orderLine struct {
+	OrderID int64  `db:"order_id" dao:"pk"`
+	LineNo  int    `db:"line_no" dao:"pk"`
+	SKU     string `db:"sku" dao:"sensitive"`
+	Ignored string `db:"-"`
+}
+
+type customer struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func (*customer) TableName() string { return "crm_customers" }
+
+func TestNamingStrategies(t *testing.T) {
+	cases := map[string][2]string{
+		"User":        {"user", "users"},
+		"UserProfile": {"user_profile", "user_profiles"},
+		"HTTPServer":  {"http_server", "http_servers"},
+		"Category":    {"category", "categories"},
+		"Day":         {"day", "days"},
+		"Address":     {"address", "addresses"},
+		"Box":         {"box", "boxes"},
+		"orderLine":   {"order_line", "order_lines"},
+	}
+	for name, want := range cases {
+		assert.Equal(t, want[0], SnakeCase(name), name)
+		assert.Equal(t, want[1], SnakeCasePlural(name), name)
+	}
+}
+
+func TestMetaOf(t *testing.T) {
+	meta := metaOf(defaultMapper, reflect.TypeOf(orderLine{}))
+	require.NoError(t, meta.err)
+	assert.Equal(t, "orderLine", meta.typeName)
+	assert.Equal(t, "", meta.tableName)
+	assert.Equal(t, []string{"order_id", "line_no", "sku"}, meta.columns)
+	assert.Equal(t, []string{"order_id", "line_no"}, meta.primaryKeys)
+	assert.Equal(t, "sku", meta.taggedColumn("sensitive"))
+	assert.Same(t, meta, metaOf(defaultMapper, reflect.TypeOf(orderLine{})), "metadata must be cached")
+
+	meta = metaOf(defaultMapper, reflect.TypeOf(customer{}))
+	assert.Equal(t, "crm_customers", meta.tableName)
+	assert.Equal(t, []string{"id"}, meta.primaryKeys)
+
+	meta = metaOf(defaultMapper, reflect.TypeOf((*any)(nil)).Elem())
+	assert.Error(t, meta.err)
+	assert.Empty(t, meta.typeName)
+	assert.Empty(t, meta.columns)
+}
+
+func TestDAO_InferTableAndFields(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t,
+		`CREATE TABLE crm_customers (id INTEGER PRIMARY KEY, name TEXT, notes TEXT)`,
+		`CREATE TABLE customer (id INTEGER PRIMARY KEY, name TEXT)`,
+	)
+
+	var events []QueryEvent
+	dao := NewDAO[customer](db, WithHooks(HookFuncs{After: func(ctx context.Context, e *QueryEvent) { events = append(events, *e) }}))
+
+	_, err := dao.InsertModel(ctx, "", &customer{ID: 1, Name: "Acme"})
+	require.NoError(t, err)
+	// notes 列不在 customer 中，推导出的字段列表避免了 SELECT * 的扫描错误
+	var customers []customer
+	require.NoError(t, dao.Select(ctx, SelectEndPoint[customer]{Model: &customers}))
+	assert.Equal(t, []customer{{ID: 1, Name: "Acme"}}, customers)
+	assert.Equal(t, "INSERT INTO `crm_customers` (`id`,`name`) VALUES (?,?)", events[0].SQL)
+	assert.Equal(t, "crm_customers", events[1].Table)
+	assert.Equal(t, "SELECT `id`,`name` FROM `crm_customers`", events[1].SQL)
+
+	// 显式的 Fields 保持不变
+	customers = nil
+	require.NoError(t, dao.Select(ctx, SelectEndPoint[customer]{Model: &customers, Fields: []string{"id"}}))
+	assert.Equal(t, "SELECT `id` FROM `crm_customers`", events[2].SQL)
+
+	type Customer struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	plain := NewDAO[Customer](db, WithNamingStrategy(SnakeCase))
+	_, err = plain.Insert(ctx, InsertEndpoint[Customer]{Rows: map[string]any{"name": "Globex"}})
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM customer"))
+	assert.Equal(t, 1, count)
+}
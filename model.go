@@ -0,0 +1,160 @@
+package db_dao
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// defaultMapper 与 sqlx 默认的映射规则保持一致（db 标签 + sqlx.NameMapper）。
+var defaultMapper = reflectx.NewMapperFunc("db", sqlx.NameMapper)
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// mapperOf 返回执行器所使用的 reflectx mapper，无法获取时退回 sqlx 的默认规则。
+func mapperOf(db Executor) *reflectx.Mapper {
+	switch e := db.(type) {
+	case *sqlx.DB:
+		if e.Mapper != nil {
+			return e.Mapper
+		}
+	case *sqlx.Tx:
+		if e.Mapper != nil {
+			return e.Mapper
+		}
+	}
+	return defaultMapper
+}
+
+// modelField 描述结构体中映射到表列的一个字段
+type modelField struct {
+	column     string
+	index      []int
+	omitEmpty  bool
+	daoOptions []string // daoOptions dao 标签中的选项，如 pk、sensitive、version
+}
+
+// modelFields 根据 db 标签解析出结构体的列字段。
+// 嵌套（非匿名）结构体的子字段、db:"-" 以及未导出字段都会被跳过；
+// 实现了 driver.Valuer 或没有可映射子字段的结构体（如 time.Time）视为单列。
+func modelFields(m *reflectx.Mapper, t reflect.Type) ([]modelField, error) {
+	t = reflectx.Deref(t)
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model must be a struct, got %v", t)
+	}
+	var fields []modelField
+	for _, fi := range m.TypeMap(t).Index {
+		if fi.Embedded || fi.Name == "" || strings.Contains(fi.Path, ".") {
+			continue
+		}
+		if !isColumnField(fi) {
+			continue
+		}
+		_, omitEmpty := fi.Options["omitempty"]
+		fields = append(fields, modelField{
+			column:     fi.Path,
+			index:      fi.Index,
+			omitEmpty:  omitEmpty,
+			daoOptions: daoOptions(fi.Field),
+		})
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("model has no db fields")
+	}
+	return fields, nil
+}
+
+func isColumnField(fi *reflectx.FieldInfo) bool {
+	ft := fi.Field.Type
+	if ft.Implements(valuerType) || reflect.PointerTo(ft).Implements(valuerType) {
+		return true
+	}
+	for _, child := range fi.Children {
+		if child != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// modelToRow 将单个结构体转换为 Insert 使用的 Rows，omitempty 字段为零值时跳过。
+func modelToRow(fields []modelField, v reflect.Value) (map[string]any, error) {
+	row := make(map[string]any, len(fields))
+	for _, f := range fields {
+		fv := fieldValue(v, f.index)
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		row[f.column] = fv.Interface()
+	}
+	if len(row) == 0 {
+		return nil, errors.New("empty rows")
+	}
+	return row, nil
+}
+
+// modelsToRows 将多个结构体转换为 BatchInsert 使用的 Rows。
+// 批量插入要求每行的列一致，因此 omitempty 字段只有在所有行都为零值时才会被跳过。
+func modelsToRows(fields []modelField, v reflect.Value) ([]map[string]any, error) {
+	n := v.Len()
+	if n == 0 {
+		return nil, errors.New("empty rows")
+	}
+	var columns []modelField
+	for _, f := range fields {
+		if !f.omitEmpty {
+			columns = append(columns, f)
+			continue
+		}
+		zeros := 0
+		for i := 0; i < n; i++ {
+			if fieldValue(v.Index(i), f.index).IsZero() {
+				zeros++
+			}
+		}
+		switch zeros {
+		case n:
+			continue
+		case 0:
+			columns = append(columns, f)
+		default:
+			return nil, fmt.Errorf("inconsistent omitempty field %s", f.column)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("empty rows")
+	}
+
+	rows := make([]map[string]any, 0, n)
+	for i := 0; i < n; i++ {
+		row := make(map[string]any, len(columns))
+		for _, f := range columns {
+			row[f.column] = fieldValue(v.Index(i), f.index).Interface()
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// fieldValue 按索引路径读取字段值；路径上遇到 nil 的匿名指针时返回该字段类型的零值。
+func fieldValue(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				t := v.Type().Elem()
+				for _, y := range index[i:] {
+					t = reflectx.Deref(t).Field(y).Type
+				}
+				return reflect.Zero(t)
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+package db_dao
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- model_test.go: Tests for deriving columns from struct tags ---
+
+type modelBase struct {
+	ID        int64     `db:"id,omitempty"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type modelAddress struct {
+	City string `db:"city"`
+}
+
+type modelRecord struct {
+	modelBase
+	Name     sql.NullString `db:"name"`
+	Address  modelAddress   `db:"address"`
+	Ignored  string         `db:"-"`
+	Nickname *string        `db:"nickname"`
+	internal string
+}
+
+func TestModelFields(t *testing.T) {
+	fields, err := modelFields(defaultMapper, reflect.TypeOf(modelRecord{}))
+	require.NoError(t, err)
+
+	var columns []string
+	for _, f := range fields {
+		columns = append(columns, f.column)
+	}
+	assert.ElementsMatch(t, []string{"id", "created_at", "name", "nickname"}, columns)
+
+	_, err = modelFields(defaultMapper, reflect.TypeOf(0))
+	assert.Error(t, err)
+}
+
+func TestModelToRow(t *testing.T) {
+	fields, err := modelFields(defaultMapper, reflect.TypeOf(modelRecord{}))
+	require.NoError(t, err)
+
+	t.Run("omitempty zero is skipped", func(t *testing.T) {
+		row, err := modelToRow(fields, reflect.ValueOf(modelRecord{Name: sql.NullString{String: "a", Valid: true}}))
+		require.NoError(t, err)
+		assert.NotContains(t, row, "id")
+		assert.Equal(t, sql.NullString{String: "a", Valid: true}, row["name"])
+		assert.Nil(t, row["nickname"])
+	})
+
+	t.Run("omitempty non-zero is kept", func(t *testing.T) {
+		row, err := modelToRow(fields, reflect.ValueOf(modelRecord{modelBase: modelBase{ID: 7}}))
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), row["id"])
+	})
+}
+
+func TestModelsToRows(t *testing.T) {
+	fields, err := modelFields(defaultMapper, reflect.TypeOf(modelRecord{}))
+	require.NoError(t, err)
+
+	rows, err := modelsToRows(fields, reflect.ValueOf([]modelRecord{{}, {}}))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.NotContains(t, rows[0], "id")
+
+	_, err = modelsToRows(fields, reflect.ValueOf([]modelRecord{{modelBase: modelBase{ID: 1}}, {}}))
+	assert.EqualError(t, err, "inconsistent omitempty field id")
+}
+
+func TestBatchInsertIDs(t *testing.T) {
+	assert.Equal(t, []int64{3, 4, 5}, batchInsertIDs(SQLite, 5, 3))
+	assert.Equal(t, []int64{5, 6, 7}, batchInsertIDs(MySQL, 5, 3))
+}
+
+func TestSetInsertID(t *testing.T) {
+	var rec modelRecord
+	require.NoError(t, setInsertID(defaultMapper, reflect.ValueOf(&rec).Elem(), "id", 42))
+	assert.Equal(t, int64(42), rec.ID)
+
+	assert.Error(t, setInsertID(defaultMapper, reflect.ValueOf(&rec).Elem(), "missing", 1))
+	assert.Error(t, setInsertID(defaultMapper, reflect.ValueOf(&rec).Elem(), "created_at", 1))
+}
@@ -0,0 +1,29 @@
+package db_dao
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrStaleObject is returned by Update on a versioned model when no row matched the expected version,
+// i.e. the row was changed (or deleted) since it was read.
+var ErrStaleObject = errors.New("stale object")
+
+// versionColumn 返回 T 中标注 `dao:"version"` 的列，没有时返回空字符串
+func (d *DAO[T]) versionColumn() string {
+	return d.meta().taggedColumn("version")
+}
+
+// versioned 将 Rows 中的版本号移到 WHERE 条件，并在 SET 中自增版本号
+func versioned[T any](dialect Dialect, column string, endpoint UpdateEndPoint[T]) (UpdateEndPoint[T], error) {
+	expected, ok := endpoint.Rows[column]
+	if !ok {
+		return endpoint, fmt.Errorf("missing version column %s in rows", column)
+	}
+	rows := maps.Clone(endpoint.Rows)
+	rows[column] = rawExpr(quoteIdent(dialect, column) + " + 1")
+	endpoint.Rows = rows
+	endpoint.Conditions = andCondition(endpoint.Conditions, map[string]any{column: Eq(expected)})
+	return endpoint, nil
+}
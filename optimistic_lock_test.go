@@ -0,0 +1,81 @@
+package db_dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- optimistic_lock_test.go: Tests for optimistic locking ---
+
+type document struct {
+	ID      int64  `db:"id"`
+	Body    string `db:"body"`
+	Version int64  `db:"version" dao:"version"`
+}
+
+var documentsSchema = []string{
+	`CREATE TABLE documents (id INTEGER PRIMARY KEY, body TEXT, version INTEGER NOT NULL)`,
+	`INSERT INTO documents (id, body, version) VALUES (1, 'draft', 1)`,
+}
+
+func TestVersioned(t *testing.T) {
+	rows := map[string]any{"body": "x", "version": int64(3)}
+	endpoint, err := versioned(SQLite, "version", UpdateEndPoint[document]{
+		Table:      "documents",
+		Rows:       rows,
+		Conditions: map[string]any{"id": Eq(1)},
+	})
+	require.NoError(t, err)
+
+	query, rowsArgs, conditionsArgs, err := endpoint.point2Sql(SQLite)
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE `documents` SET `body` = ?,`version` = `version` + 1 WHERE ((`id` = ?)) AND ((`version` = ?))", query)
+	assert.Equal(t, []any{"x"}, rowsArgs)
+	assert.Equal(t, []any{1, int64(3)}, conditionsArgs)
+	// 调用方的 Rows 不被修改
+	assert.Equal(t, int64(3), rows["version"])
+
+	_, err = versioned(SQLite, "version", UpdateEndPoint[document]{Table: "documents", Rows: map[string]any{"body": "x"}})
+	assert.EqualError(t, err, "missing version column version in rows")
+}
+
+func TestUpdate_OptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[document](newSQLiteDB(t, documentsSchema...))
+
+	var first, second document
+	require.NoError(t, dao.Get(ctx, GetEndPoint[document]{Model: &first, Table: "documents", Conditions: map[string]any{"id": Eq(1)}}))
+	second = first
+
+	affected, err := dao.Update(ctx, UpdateEndPoint[document]{
+		Table:      "documents",
+		Rows:       map[string]any{"body": "first edit", "version": first.Version},
+		Conditions: map[string]any{"id": Eq(1)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	// 第二个编辑者持有旧版本号
+	affected, err = dao.Update(ctx, UpdateEndPoint[document]{
+		Table:      "documents",
+		Rows:       map[string]any{"body": "second edit", "version": second.Version},
+		Conditions: map[string]any{"id": Eq(1)},
+	})
+	assert.ErrorIs(t, err, ErrStaleObject)
+	assert.Equal(t, int64(0), affected)
+
+	var current document
+	require.NoError(t, dao.Get(ctx, GetEndPoint[document]{Model: &current, Table: "documents", Conditions: map[string]any{"id": Eq(1)}}))
+	assert.Equal(t, "first edit", current.Body)
+	assert.Equal(t, int64(2), current.Version)
+
+	_, err = dao.Update(ctx, UpdateEndPoint[document]{
+		Table:      "documents",
+		Rows:       map[string]any{"body": "no version"},
+		Conditions: map[string]any{"id": Eq(1)},
+	})
+	assert.EqualError(t, err, "missing version column version in rows")
+}
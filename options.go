@@ -0,0 +1,86 @@
+package db_dao
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// Option configures a DAO created by NewDAO.
+type Option func(*config)
+
+// config holds the settings shared by a DAO and the transactional DAOs derived from it.
+type config struct {
+	dialect      Dialect
+	cursorSecret []byte
+	hooks        []Hook
+	sensitive    map[string]map[string]bool // table -> columns
+	softDelete   string
+	createdAt    *string // createdAt / updatedAt 由 WithTimestamps 设置，nil 表示使用 T 的标签
+	updatedAt    *string
+	now          func() time.Time
+	naming       NamingStrategy
+
+	validateColumns bool            // validateColumns 由 WithColumnValidation / WithStrictMode 开启
+	allowedColumns  map[string]bool // allowedColumns T 的列以外额外允许的列
+	strict          bool            // strict 拒绝非 QueryBuilder 生成的 Appends
+}
+
+func newConfig(db Executor, opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if len(cfg.cursorSecret) == 0 {
+		cfg.cursorSecret = defaultCursorSecret
+	}
+	if cfg.naming == nil {
+		cfg.naming = SnakeCasePlural
+	}
+	if cfg.now == nil {
+		cfg.now = time.Now
+	}
+	if cfg.dialect == nil {
+		cfg.dialect = DialectFor(driverNameOf(db))
+	}
+	return cfg
+}
+
+// executorConfig 返回与模型无关的设置 (方言、钩子、脱敏列、游标密钥、时钟、命名策略)，供工作单元中
+// 不同模型的 DAO 共享；软删除、时间戳与列校验只对配置它们的模型有意义，不会传递。
+// 切片与 map 均为副本，之后应用的 Option 不会影响 c
+func (c *config) executorConfig() *config {
+	shared := &config{
+		dialect:      c.dialect,
+		cursorSecret: c.cursorSecret,
+		hooks:        slices.Clip(c.hooks),
+		now:          c.now,
+		naming:       c.naming,
+	}
+	if c.sensitive != nil {
+		shared.sensitive = make(map[string]map[string]bool, len(c.sensitive))
+		for table, columns := range c.sensitive {
+			shared.sensitive[table] = maps.Clone(columns)
+		}
+	}
+	return shared
+}
+
+// WithDialect sets the SQL dialect explicitly instead of detecting it from the driver name.
+// It is required when the driver name cannot be detected, i.e. the Executor implements neither
+// DriverName() (like *sqlx.DB / *sqlx.Tx) nor Unwrap() Executor, or when DialectFor does not recognise
+// the driver; such a DAO otherwise fails every statement with ErrUnknownDialect.
+func WithDialect(d Dialect) Option {
+	return func(c *config) {
+		c.dialect = d
+	}
+}
+
+// WithCursorSecret sets the HMAC key used to sign PaginateCursor tokens.
+// Without it a random per-process key is used, so tokens do not survive restarts
+// and are not accepted by other instances.
+func WithCursorSecret(secret []byte) Option {
+	return func(c *config) {
+		c.cursorSecret = secret
+	}
+}
@@ -7,24 +7,24 @@ import (
 )
 
 // for count
-func (s PageEndPoint[T]) point2Sql() (string, []any, error) {
-	tableQuery, err := buildTableClause(s.Table)
+func (s PageEndPoint[T]) point2Sql(dialect Dialect) (string, []any, error) {
+	tableQuery, joinArgs, err := buildFromClause(dialect, s.Table, s.Joins)
 	if err != nil {
 		return "", nil, err
 	}
 
-	conditionsQuery, conditionsArgs, err := buildWhereClause(s.Conditions)
+	conditionsQuery, conditionsArgs, err := buildWhereClause(dialect, s.Conditions)
 	if err != nil {
 		return "", nil, err
 	}
 
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %v %v", tableQuery, conditionsQuery)
 
-	return query, conditionsArgs, nil
+	return query, append(joinArgs, conditionsArgs...), nil
 }
 
 // for select
-func (s PageEndPoint[T]) point2pageSql() (string, []any, error) {
+func (s PageEndPoint[T]) point2pageSql(dialect Dialect) (string, []any, error) {
 	if s.PageNo < 1 {
 		return "", nil, errors.New("pageNo must be >= 1")
 	}
@@ -32,14 +32,14 @@ func (s PageEndPoint[T]) point2pageSql() (string, []any, error) {
 		return "", nil, errors.New("pageSize must be >= 1")
 	}
 
-	fieldsQuery := buildFieldsClause(s.Fields)
+	fieldsQuery := buildFieldsClause(dialect, s.Fields)
 
-	tableQuery, err := buildTableClause(s.Table)
+	tableQuery, joinArgs, err := buildFromClause(dialect, s.Table, s.Joins)
 	if err != nil {
 		return "", nil, err
 	}
 
-	conditionsQuery, conditionsArgs, err := buildWhereClause(s.Conditions)
+	conditionsQuery, conditionsArgs, err := buildWhereClause(dialect, s.Conditions)
 	if err != nil {
 		return "", nil, err
 	}
@@ -52,16 +52,26 @@ func (s PageEndPoint[T]) point2pageSql() (string, []any, error) {
 		queryBuilder.WriteString(conditionsQuery)
 	}
 
-	if s.SortField != "" {
+	ordered := s.SortField != "" || len(s.SortKeys) > 0
+	if len(s.SortKeys) > 0 {
+		orderBy, err := buildOrderByClause(dialect, s.SortKeys)
+		if err != nil {
+			return "", nil, err
+		}
+		queryBuilder.WriteString(" ")
+		queryBuilder.WriteString(orderBy)
+	} else if ordered {
 		order := "ASC" // 默认为 ASC
 		if strings.ToUpper(s.SortOrder) == "DESC" {
 			order = "DESC"
 		}
-		// 注意：为了最大程度的安全，SortField 应根据业务逻辑进行白名单验证。
+		// 注意：SortField 原样拼接进 SQL，来自用户输入时请启用 WithColumnValidation 或自行白名单验证。
 		queryBuilder.WriteString(fmt.Sprintf(" ORDER BY %s %s", s.SortField, order))
 	}
 
-	queryBuilder.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", s.PageSize, (s.PageNo-1)*s.PageSize))
+	limit := int64(s.PageSize)
+	queryBuilder.WriteString(" ")
+	queryBuilder.WriteString(dialect.LimitOffset(limit, int64(s.PageNo-1)*limit, ordered))
 
-	return queryBuilder.String(), conditionsArgs, nil
+	return queryBuilder.String(), append(joinArgs, conditionsArgs...), nil
 }
@@ -0,0 +1,197 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrNoPrimaryKey is returned by the *ByID helpers when T has no primary key column.
+var ErrNoPrimaryKey = errors.New("model has no primary key")
+
+// primaryKeyValues 将 id 展开为与主键列一一对应的值。复合主键需传入 []any。
+func (d *DAO[T]) primaryKeyValues(id any) ([]string, []any, error) {
+	keys := d.meta().primaryKeys
+	if len(keys) == 0 {
+		return nil, nil, ErrNoPrimaryKey
+	}
+	values, ok := id.([]any)
+	if !ok {
+		values = []any{id}
+	}
+	if len(values) != len(keys) {
+		return nil, nil, fmt.Errorf("primary key has %d columns, got %d values", len(keys), len(values))
+	}
+	return keys, values, nil
+}
+
+// primaryKeyCondition 生成匹配单个主键的条件
+func (d *DAO[T]) primaryKeyCondition(id any) (map[string]any, error) {
+	keys, values, err := d.primaryKeyValues(id)
+	if err != nil {
+		return nil, err
+	}
+	conditions := make(map[string]any, len(keys))
+	for i, k := range keys {
+		conditions[k] = Eq(values[i])
+	}
+	return conditions, nil
+}
+
+// primaryKeyString 将主键值转换为可比较的字符串。每个值先按 database/sql 的规则转换为 driver.Value，
+// 因此 int / int64、sql.NullInt64 等 Valuer、驱动返回的 []byte 与 string、不同时区的 time.Time 得到相同的结果。
+// fold 为 true 时字符串忽略大小写与尾部空格，用于匹配排序规则不区分这些差异的数据库返回的主键
+func primaryKeyString(values []any, fold bool) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if dv, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+			v = dv
+		}
+		switch dv := v.(type) {
+		case nil:
+			parts[i] = "\x01NULL"
+		case time.Time:
+			parts[i] = dv.UTC().Format(time.RFC3339Nano)
+		case []byte:
+			parts[i] = foldKey(string(dv), fold)
+		case string:
+			parts[i] = foldKey(dv, fold)
+		default:
+			parts[i] = fmt.Sprint(dv)
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func foldKey(s string, fold bool) string {
+	if !fold {
+		return s
+	}
+	return strings.ToLower(strings.TrimRight(s, " "))
+}
+
+// FindByID returns the row whose primary key equals id (use []any for composite keys).
+// It returns sql.ErrNoRows when no row matches.
+func (d *DAO[T]) FindByID(ctx context.Context, id any) (*T, error) {
+	conditions, err := d.primaryKeyCondition(id)
+	if err != nil {
+		return nil, err
+	}
+	var model T
+	if err := d.Get(ctx, GetEndPoint[T]{Model: &model, Conditions: conditions}); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// FindByIDs returns the rows whose primary keys are in ids, in the order of ids.
+// Keys without a matching row are returned in missing, also in the order of ids.
+func (d *DAO[T]) FindByIDs(ctx context.Context, ids []any) (rows []T, missing []any, err error) {
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+	keys := d.meta().primaryKeys
+	var conditions Condition
+	if len(keys) == 1 {
+		values := make([]any, len(ids))
+		for i, id := range ids {
+			_, v, err := d.primaryKeyValues(id)
+			if err != nil {
+				return nil, nil, err
+			}
+			values[i] = v[0]
+		}
+		conditions = map[string]any{keys[0]: In(values...)}
+	} else {
+		or := make(Or, len(ids))
+		for i, id := range ids {
+			if or[i], err = d.primaryKeyCondition(id); err != nil {
+				return nil, nil, err
+			}
+		}
+		conditions = or
+	}
+
+	var found []T
+	if err := d.Select(ctx, SelectEndPoint[T]{Model: &found, Conditions: conditions}); err != nil {
+		return nil, nil, err
+	}
+
+	// 按主键建立索引，再按 ids 的顺序输出
+	indexes := make([][]int, len(keys))
+	for _, f := range d.meta().fields {
+		for i, k := range keys {
+			if f.column == k {
+				indexes[i] = f.index
+			}
+		}
+	}
+	exact := make(map[string]int, len(found))
+	folded := make(map[string][]int, len(found))
+	for i := range found {
+		v := reflect.ValueOf(&found[i]).Elem()
+		values := make([]any, len(keys))
+		for j, index := range indexes {
+			values[j] = fieldValue(v, index).Interface()
+		}
+		exact[primaryKeyString(values, false)] = i
+		key := primaryKeyString(values, true)
+		folded[key] = append(folded[key], i)
+	}
+	matched := make([]int, len(ids))
+	claimed := make([]bool, len(found))
+	for j, id := range ids {
+		_, values, _ := d.primaryKeyValues(id)
+		i, ok := exact[primaryKeyString(values, false)]
+		if !ok {
+			i = -1
+		} else {
+			claimed[i] = true
+		}
+		matched[j] = i
+	}
+	// 不区分大小写的排序规则下 "alice" 会查到 "Alice"，此时只匹配没有被其他 id 精确匹配的行
+	for j, id := range ids {
+		if matched[j] >= 0 {
+			continue
+		}
+		_, values, _ := d.primaryKeyValues(id)
+		for _, i := range folded[primaryKeyString(values, true)] {
+			if !claimed[i] {
+				matched[j] = i
+				break
+			}
+		}
+	}
+	for j, id := range ids {
+		if i := matched[j]; i >= 0 {
+			rows = append(rows, found[i])
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return rows, missing, nil
+}
+
+// UpdateByID updates the row whose primary key equals id with rows.
+// Versioning and automatic timestamps apply as in Update.
+func (d *DAO[T]) UpdateByID(ctx context.Context, id any, rows map[string]any) (int64, error) {
+	conditions, err := d.primaryKeyCondition(id)
+	if err != nil {
+		return 0, err
+	}
+	return d.Update(ctx, UpdateEndPoint[T]{Rows: rows, Conditions: conditions})
+}
+
+// DeleteByID deletes the row whose primary key equals id; soft deletion applies as in Delete.
+func (d *DAO[T]) DeleteByID(ctx context.Context, id any) (int64, error) {
+	conditions, err := d.primaryKeyCondition(id)
+	if err != nil {
+		return 0, err
+	}
+	return d.Delete(ctx, DeleteEndPoint[T]{Conditions: conditions})
+}
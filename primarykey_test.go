@@ -0,0 +1,144 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- primarykey_test.go: Tests for the primary-key helpers ---
+
+var orderLinesSchema = []string{
+	`CREATE TABLE order_lines (order_id INTEGER, line_no INTEGER, sku TEXT, PRIMARY KEY (order_id, line_no))`,
+	`INSERT INTO order_lines VALUES (1, 1, 'a'), (1, 2, 'b'), (2, 1, 'c')`,
+}
+
+func (s *DAOTestSuite) TestFindByID() {
+	ctx := context.Background()
+	user, err := s.userDAO.FindByID(ctx, 2)
+	s.Require().NoError(err)
+	s.Equal("Bob", user.Name)
+
+	_, err = s.userDAO.FindByID(ctx, 99)
+	s.ErrorIs(err, sql.ErrNoRows)
+
+	_, err = s.userDAO.FindByID(ctx, []any{1, 2})
+	s.EqualError(err, "primary key has 1 columns, got 2 values")
+}
+
+func (s *DAOTestSuite) TestFindByIDs() {
+	ctx := context.Background()
+	ids := []any{2, 99, int64(1), 2}
+	users, missing, err := s.userDAO.FindByIDs(ctx, ids)
+	s.Require().NoError(err)
+	s.Require().Len(users, 3)
+	s.Equal([]string{"Bob", "Alice", "Bob"}, []string{users[0].Name, users[1].Name, users[2].Name})
+	s.Equal([]any{99}, missing)
+	s.Equal([]any{2, 99, int64(1), 2}, ids, "input must not change")
+
+	users, missing, err = s.userDAO.FindByIDs(ctx, nil)
+	s.NoError(err)
+	s.Nil(users)
+	s.Nil(missing)
+}
+
+func (s *DAOTestSuite) TestUpdateAndDeleteByID() {
+	ctx := context.Background()
+	affected, err := s.userDAO.UpdateByID(ctx, 1, map[string]any{"age": 31})
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+	user, err := s.userDAO.FindByID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal(31, user.Age)
+
+	affected, err = s.userDAO.DeleteByID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+	_, err = s.userDAO.FindByID(ctx, 1)
+	s.ErrorIs(err, sql.ErrNoRows)
+}
+
+func TestPrimaryKey_Composite(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[orderLine](newSQLiteDB(t, orderLinesSchema...))
+
+	line, err := dao.FindByID(ctx, []any{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, "b", line.SKU)
+
+	_, err = dao.FindByID(ctx, 1)
+	assert.EqualError(t, err, "primary key has 2 columns, got 1 values")
+
+	lines, missing, err := dao.FindByIDs(ctx, []any{[]any{2, 1}, []any{3, 3}, []any{1, 1}})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "c", lines[0].SKU)
+	assert.Equal(t, "a", lines[1].SKU)
+	assert.Equal(t, []any{[]any{3, 3}}, missing)
+
+	affected, err := dao.UpdateByID(ctx, []any{1, 1}, map[string]any{"sku": "z"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	affected, err = dao.DeleteByID(ctx, []any{1, 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+}
+
+func TestFindByIDs_KeyTypes(t *testing.T) {
+	type account struct {
+		ID   sql.NullInt64 `db:"id"`
+		Name string        `db:"name"`
+	}
+	type tag struct {
+		Code  string    `db:"code" dao:"pk"`
+		Since time.Time `db:"since" dao:"pk"`
+	}
+	ctx := context.Background()
+	db := newSQLiteDB(t,
+		`CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO accounts VALUES (1, 'a'), (2, 'b')`,
+	)
+
+	// Valuer 类型的主键与 int 的 id 按 driver.Value 比较
+	accounts, missing, err := NewDAO[account](db).FindByIDs(ctx, []any{2, int64(1), 3})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "b", accounts[0].Name)
+	assert.Equal(t, "a", accounts[1].Name)
+	assert.Equal(t, []any{3}, missing)
+
+	// 不区分大小写的排序规则返回的主键与 id 写法不同
+	_, err = db.Exec(`CREATE TABLE tags (code TEXT COLLATE NOCASE, since DATETIME, PRIMARY KEY (code, since))`)
+	require.NoError(t, err)
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = db.Exec(`INSERT INTO tags VALUES (?, ?), (?, ?)`, "Go", since, "sql", since)
+	require.NoError(t, err)
+	tags, missing, err := NewDAO[tag](db).FindByIDs(ctx, []any{[]any{"go", since}, []any{"SQL", since}, []any{"rust", since}})
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	assert.Equal(t, "Go", tags[0].Code)
+	assert.Equal(t, "sql", tags[1].Code)
+	assert.Equal(t, []any{[]any{"rust", since}}, missing)
+
+	// 驱动返回的 []byte 与 string、不同时区表示的同一时刻同样视为相等
+	local := since.In(time.FixedZone("UTC+8", 8*3600))
+	assert.Equal(t, primaryKeyString([]any{"go", since}, false), primaryKeyString([]any{[]byte("go"), local}, false))
+	assert.NotEqual(t, primaryKeyString([]any{"go"}, false), primaryKeyString([]any{"Go "}, false))
+	assert.Equal(t, primaryKeyString([]any{"go"}, true), primaryKeyString([]any{"Go "}, true))
+}
+
+func TestPrimaryKey_Missing(t *testing.T) {
+	type noKey struct {
+		Name string `db:"name"`
+	}
+	dao := NewDAO[noKey](nil)
+	_, err := dao.FindByID(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrNoPrimaryKey)
+	_, _, err = dao.FindByIDs(context.Background(), []any{1})
+	assert.ErrorIs(t, err, ErrNoPrimaryKey)
+}
@@ -0,0 +1,103 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// projectionFields 返回投影查询的列：未指定时使用 R 的 db 列，有连接时以 qualifier 限定
+func projectionFields[R, T any](d *DAO[T], fields []string, qualifier string) ([]string, error) {
+	if len(fields) > 0 {
+		return fields, nil
+	}
+	columns := metaOf(mapperOf(d.db), reflect.TypeOf((*R)(nil)).Elem()).columns
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("empty fields for result type %T", *new(R))
+	}
+	qualified := make([]string, len(columns))
+	for i, c := range columns {
+		qualified[i] = qualify(qualifier, c)
+	}
+	return qualified, nil
+}
+
+// SelectAs runs endpoint like DAO.Select but scans the rows into dest instead of endpoint.Model,
+// so a DTO or a subset of columns can be read without a second DAO. When endpoint.Fields is empty,
+// the db columns of R are selected; list Fields explicitly when R has columns of joined tables.
+//
+//	type userName struct {
+//		ID   int64  `db:"id"`
+//		Name string `db:"name"`
+//	}
+//	var names []userName
+//	err := db_dao.SelectAs(ctx, userDAO, db_dao.SelectEndPoint[User]{
+//		Conditions: map[string]any{"age": db_dao.Gte(18)},
+//	}, &names)
+func SelectAs[T, R any](ctx context.Context, dao IDAO[T], endpoint SelectEndPoint[T], dest *[]R) error {
+	if dest == nil {
+		return errors.New("nil model")
+	}
+	d, err := daoOf(dao)
+	if err != nil {
+		return err
+	}
+	endpoint.Fields, err = projectionFields[R](d, endpoint.Fields, tableQualifier(d.table(endpoint.Table), endpoint.Joins))
+	if err != nil {
+		return err
+	}
+	table, query, args, err := d.selectQuery(endpoint)
+	if err != nil {
+		return err
+	}
+	return d.selectContext(ctx, "SelectAs", table, dest, query, args)
+}
+
+// GetAs runs endpoint like DAO.Get but scans the row into dest instead of endpoint.Model.
+// Fields default to the db columns of R, as in SelectAs.
+func GetAs[T, R any](ctx context.Context, dao IDAO[T], endpoint GetEndPoint[T], dest *R) error {
+	if dest == nil {
+		return errors.New("nil model")
+	}
+	d, err := daoOf(dao)
+	if err != nil {
+		return err
+	}
+	endpoint.Fields, err = projectionFields[R](d, endpoint.Fields, tableQualifier(d.table(endpoint.Table), endpoint.Joins))
+	if err != nil {
+		return err
+	}
+	table, query, args, err := d.getQuery(endpoint)
+	if err != nil {
+		return err
+	}
+	return d.getContext(ctx, "GetAs", table, dest, query, args)
+}
+
+// Pluck returns a single column of the rows matching endpoint, e.g. the ids of adult users:
+//
+//	ids, err := db_dao.Pluck[int64](ctx, userDAO, "id", db_dao.SelectEndPoint[User]{
+//		Conditions: map[string]any{"age": db_dao.Gte(18)},
+//	})
+//
+// endpoint.Fields and endpoint.Model are ignored.
+func Pluck[V, T any](ctx context.Context, dao IDAO[T], column string, endpoint SelectEndPoint[T]) ([]V, error) {
+	d, err := daoOf(dao)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateIdentifier(column); err != nil {
+		return nil, err
+	}
+	endpoint.Fields = []string{column}
+	table, query, args, err := d.selectQuery(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var values []V
+	if err := d.selectContext(ctx, "Pluck", table, &values, query, args); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
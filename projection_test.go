@@ -0,0 +1,98 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- projection_test.go: Tests for SelectAs / GetAs / Pluck ---
+
+type userName struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestProjectionFields(t *testing.T) {
+	dao := NewDAO[User](nil)
+	fields, err := projectionFields[userName](dao, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, fields)
+
+	fields, err = projectionFields[userName](dao, nil, "u")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u.id", "u.name"}, fields)
+
+	fields, err = projectionFields[userName](dao, []string{"name AS id"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name AS id"}, fields)
+
+	_, err = projectionFields[int64](dao, nil, "")
+	assert.EqualError(t, err, "empty fields for result type int64")
+}
+
+func (s *DAOTestSuite) TestSelectAs() {
+	ctx := context.Background()
+	var names []userName
+	err := SelectAs(ctx, s.userDAO, SelectEndPoint[User]{
+		Conditions: map[string]any{"age": Gte(30)},
+		Appends:    []string{"ORDER BY id DESC"},
+	}, &names)
+	s.Require().NoError(err)
+	s.Equal([]userName{{ID: 2, Name: "Bob"}, {ID: 1, Name: "Alice"}}, names)
+
+	var name userName
+	err = GetAs(ctx, s.userDAO, GetEndPoint[User]{Conditions: map[string]any{"id": Eq(2)}}, &name)
+	s.Require().NoError(err)
+	s.Equal(userName{ID: 2, Name: "Bob"}, name)
+
+	err = GetAs(ctx, s.userDAO, GetEndPoint[User]{Conditions: map[string]any{"id": Eq(3)}}, &name)
+	s.ErrorIs(err, sql.ErrNoRows)
+
+	var age int
+	err = GetAs(ctx, s.userDAO, GetEndPoint[User]{Fields: []string{"age"}, Conditions: map[string]any{"id": Eq(1)}}, &age)
+	s.Require().NoError(err)
+	s.Equal(30, age)
+
+	ids, err := Pluck[int64](ctx, s.userDAO, "id", SelectEndPoint[User]{Appends: []string{"ORDER BY id"}})
+	s.Require().NoError(err)
+	s.Equal([]int64{1, 2}, ids)
+
+	userNames, err := Pluck[string](ctx, s.userDAO, "name", SelectEndPoint[User]{Conditions: map[string]any{"age": Gt(30)}})
+	s.Require().NoError(err)
+	s.Equal([]string{"Bob"}, userNames)
+
+	_, err = Pluck[string](ctx, s.userDAO, "name; DROP TABLE users", SelectEndPoint[User]{})
+	s.Error(err)
+}
+
+func TestProjectionSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[post](newSQLiteDB(t, postsSchema...))
+	_, err := dao.Delete(ctx, DeleteEndPoint[post]{Table: "posts", Conditions: map[string]any{"id": Eq(2)}})
+	require.NoError(t, err)
+
+	titles, err := Pluck[string](ctx, dao, "title", SelectEndPoint[post]{Table: "posts", Appends: []string{"ORDER BY id"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, titles)
+
+	titles, err = Pluck[string](ctx, dao.WithTrashed(), "title", SelectEndPoint[post]{Table: "posts", Appends: []string{"ORDER BY id"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, titles)
+}
+
+func TestProjectionUnsupportedDAO(t *testing.T) {
+	ctx := context.Background()
+	// 包装了 IDAO 的装饰器无法取得 DAO 的配置，返回错误而不是绕过装饰器
+	wrapped := struct{ IDAO[User] }{NewDAO[User](newSQLiteDB(t, createUsersTable, seedUsers))}
+
+	_, err := Pluck[int64](ctx, wrapped, "id", SelectEndPoint[User]{})
+	assert.ErrorIs(t, err, ErrUnsupportedDAO)
+	var names []User
+	assert.ErrorIs(t, SelectAs(ctx, wrapped, SelectEndPoint[User]{}, &names), ErrUnsupportedDAO)
+	_, err = TxOf(wrapped)
+	assert.ErrorIs(t, err, ErrUnsupportedDAO)
+}
@@ -0,0 +1,298 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// QueryBuilder 链式查询构建器，由 DAO.Query 创建。
+// 每个终结方法 (Select / First / Count / Exists / Paginate / Update / Delete) 都会先编译为对应的
+// endpoint 结构体再交给 DAO 执行，To* 方法返回编译结果，便于与 endpoint 写法混用：
+//
+//	var users []User
+//	err := userDAO.Query().
+//		Where(map[string]any{"age": db_dao.Gte(18)}).
+//		OrderByDesc("created_at").
+//		Limit(20).
+//		Select(ctx, &users)
+//
+// QueryBuilder 不是并发安全的，每个查询应使用新的构建器。
+type QueryBuilder[T any] struct {
+	dao        *DAO[T]
+	table      string
+	joins      []Join
+	fields     []string
+	conditions []Condition
+	orderBy    []SortKey
+	limit      int64
+	offset     int64
+	err        error
+}
+
+// Query starts a fluent query on the DAO's table (derived from T unless Table is called).
+func (d *DAO[T]) Query() *QueryBuilder[T] {
+	return &QueryBuilder[T]{dao: d}
+}
+
+// Table sets the table, overriding the one derived from T.
+func (q *QueryBuilder[T]) Table(table string) *QueryBuilder[T] {
+	q.table = table
+	return q
+}
+
+// Join adds an INNER JOIN of table (optionally aliased, e.g. "orders o") on condition.
+func (q *QueryBuilder[T]) Join(table string, on Condition) *QueryBuilder[T] {
+	return q.join(InnerJoin, table, on)
+}
+
+// LeftJoin adds a LEFT JOIN of table on condition.
+func (q *QueryBuilder[T]) LeftJoin(table string, on Condition) *QueryBuilder[T] {
+	return q.join(LeftJoin, table, on)
+}
+
+// RightJoin adds a RIGHT JOIN of table on condition.
+func (q *QueryBuilder[T]) RightJoin(table string, on Condition) *QueryBuilder[T] {
+	return q.join(RightJoin, table, on)
+}
+
+func (q *QueryBuilder[T]) join(joinType JoinType, table string, on Condition) *QueryBuilder[T] {
+	q.joins = append(q.joins, Join{Type: joinType, Table: table, On: on})
+	return q
+}
+
+// Fields sets the selected columns; by default all columns of T are selected.
+func (q *QueryBuilder[T]) Fields(fields ...string) *QueryBuilder[T] {
+	q.fields = fields
+	return q
+}
+
+// Where adds a condition; multiple calls are combined with AND.
+func (q *QueryBuilder[T]) Where(condition Condition) *QueryBuilder[T] {
+	if condition != nil {
+		q.conditions = append(q.conditions, condition)
+	}
+	return q
+}
+
+// OrderBy appends an ascending sort column.
+func (q *QueryBuilder[T]) OrderBy(column string) *QueryBuilder[T] {
+	return q.order(column, false)
+}
+
+// OrderByDesc appends a descending sort column.
+func (q *QueryBuilder[T]) OrderByDesc(column string) *QueryBuilder[T] {
+	return q.order(column, true)
+}
+
+func (q *QueryBuilder[T]) order(column string, desc bool) *QueryBuilder[T] {
+	if err := validateIdentifier(column); err != nil && q.err == nil {
+		q.err = err
+	}
+	q.orderBy = append(q.orderBy, SortKey{Column: column, Desc: desc})
+	return q
+}
+
+// Limit sets the maximum number of rows returned by Select.
+func (q *QueryBuilder[T]) Limit(limit int) *QueryBuilder[T] {
+	q.limit = int64(limit)
+	return q
+}
+
+// Offset sets the number of rows skipped by Select / First.
+func (q *QueryBuilder[T]) Offset(offset int) *QueryBuilder[T] {
+	q.offset = int64(offset)
+	return q
+}
+
+// condition 将多次 Where 合并为一个条件
+func (q *QueryBuilder[T]) condition() Condition {
+	switch len(q.conditions) {
+	case 0:
+		return nil
+	case 1:
+		return q.conditions[0]
+	}
+	return And(q.conditions)
+}
+
+// appends 生成 ORDER BY 与 LIMIT/OFFSET 子句
+func (q *QueryBuilder[T]) appends(limit int64) ([]string, error) {
+	var appends []string
+	if len(q.orderBy) > 0 {
+		orderBy, err := buildOrderByClause(q.dao.cfg.dialect, q.orderBy)
+		if err != nil {
+			return nil, err
+		}
+		appends = append(appends, orderBy)
+	}
+	if page := q.dao.cfg.dialect.LimitOffset(limit, q.offset, len(q.orderBy) > 0); page != "" {
+		appends = append(appends, page)
+	}
+	return appends, nil
+}
+
+// compiled 记录生成的 Appends，使其在严格模式下可以执行
+func (q *QueryBuilder[T]) compiled(appends []string) *compiled {
+	if len(appends) == 0 {
+		return nil
+	}
+	return &compiled{appends: slices.Clone(appends), sortKeys: q.orderBy}
+}
+
+// ToSelect compiles the query to a SelectEndPoint scanning into model.
+func (q *QueryBuilder[T]) ToSelect(model *[]T) (SelectEndPoint[T], error) {
+	if q.err != nil {
+		return SelectEndPoint[T]{}, q.err
+	}
+	appends, err := q.appends(q.limit)
+	if err != nil {
+		return SelectEndPoint[T]{}, err
+	}
+	return SelectEndPoint[T]{
+		Model:      model,
+		Table:      q.table,
+		Joins:      q.joins,
+		Conditions: q.condition(),
+		Appends:    appends,
+		Fields:     q.fields,
+		compiled:   q.compiled(appends),
+	}, nil
+}
+
+// ToGet compiles the query to a GetEndPoint that reads the first matching row into model.
+func (q *QueryBuilder[T]) ToGet(model *T) (GetEndPoint[T], error) {
+	if q.err != nil {
+		return GetEndPoint[T]{}, q.err
+	}
+	appends, err := q.appends(1)
+	if err != nil {
+		return GetEndPoint[T]{}, err
+	}
+	return GetEndPoint[T]{
+		Model:      model,
+		Table:      q.table,
+		Joins:      q.joins,
+		Conditions: q.condition(),
+		Appends:    appends,
+		Fields:     q.fields,
+		compiled:   q.compiled(appends),
+	}, nil
+}
+
+// ToPage compiles the query to a PageEndPoint. Limit and Offset are replaced by pageNo / pageSize.
+func (q *QueryBuilder[T]) ToPage(model *[]T, pageNo, pageSize int32) (PageEndPoint[T], error) {
+	if q.err != nil {
+		return PageEndPoint[T]{}, q.err
+	}
+	return PageEndPoint[T]{
+		Model:      model,
+		Table:      q.table,
+		Joins:      q.joins,
+		Conditions: q.condition(),
+		SortKeys:   q.orderBy,
+		PageNo:     pageNo,
+		PageSize:   pageSize,
+		Fields:     q.fields,
+	}, nil
+}
+
+// ToUpdate compiles the query to an UpdateEndPoint setting rows.
+func (q *QueryBuilder[T]) ToUpdate(rows map[string]any) (UpdateEndPoint[T], error) {
+	if err := q.checkWrite("update"); err != nil {
+		return UpdateEndPoint[T]{}, err
+	}
+	return UpdateEndPoint[T]{Table: q.table, Rows: rows, Conditions: q.condition()}, nil
+}
+
+// ToDelete compiles the query to a DeleteEndPoint.
+func (q *QueryBuilder[T]) ToDelete() (DeleteEndPoint[T], error) {
+	if err := q.checkWrite("delete"); err != nil {
+		return DeleteEndPoint[T]{}, err
+	}
+	return DeleteEndPoint[T]{Table: q.table, Conditions: q.condition()}, nil
+}
+
+// checkWrite 拒绝在 Update / Delete 上使用只对查询生效的设置，避免误以为只影响部分行
+func (q *QueryBuilder[T]) checkWrite(op string) error {
+	if q.err != nil {
+		return q.err
+	}
+	if len(q.orderBy) > 0 || q.limit > 0 || q.offset > 0 {
+		return errors.New("order by, limit and offset are not supported for " + op)
+	}
+	if len(q.joins) > 0 {
+		return errors.New("joins are not supported for " + op)
+	}
+	return nil
+}
+
+// Select runs the query and stores all matching rows in model.
+func (q *QueryBuilder[T]) Select(ctx context.Context, model *[]T) error {
+	endpoint, err := q.ToSelect(model)
+	if err != nil {
+		return err
+	}
+	return q.dao.Select(ctx, endpoint)
+}
+
+// First returns the first matching row, or sql.ErrNoRows.
+func (q *QueryBuilder[T]) First(ctx context.Context) (*T, error) {
+	var model T
+	endpoint, err := q.ToGet(&model)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.dao.Get(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// Count returns the number of matching rows, ignoring order, limit and offset.
+func (q *QueryBuilder[T]) Count(ctx context.Context) (int64, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
+	return q.dao.Count(ctx, q.toAggregate())
+}
+
+// Exists reports whether any row matches, ignoring order, limit and offset.
+func (q *QueryBuilder[T]) Exists(ctx context.Context) (bool, error) {
+	if q.err != nil {
+		return false, q.err
+	}
+	return q.dao.Exists(ctx, q.toAggregate())
+}
+
+// toAggregate 只保留表、连接与条件
+func (q *QueryBuilder[T]) toAggregate() AggregateEndPoint[T] {
+	return AggregateEndPoint[T]{Table: q.table, Joins: q.joins, Conditions: q.condition()}
+}
+
+// Paginate runs the query as page pageNo of size pageSize and returns the total row count.
+func (q *QueryBuilder[T]) Paginate(ctx context.Context, model *[]T, pageNo, pageSize int32) (int64, error) {
+	endpoint, err := q.ToPage(model, pageNo, pageSize)
+	if err != nil {
+		return 0, err
+	}
+	return q.dao.Paginate(ctx, endpoint)
+}
+
+// Update sets rows on all matching rows.
+func (q *QueryBuilder[T]) Update(ctx context.Context, rows map[string]any) (int64, error) {
+	endpoint, err := q.ToUpdate(rows)
+	if err != nil {
+		return 0, err
+	}
+	return q.dao.Update(ctx, endpoint)
+}
+
+// Delete deletes all matching rows (soft deletion applies as in DAO.Delete).
+func (q *QueryBuilder[T]) Delete(ctx context.Context) (int64, error) {
+	endpoint, err := q.ToDelete()
+	if err != nil {
+		return 0, err
+	}
+	return q.dao.Delete(ctx, endpoint)
+}
@@ -0,0 +1,90 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- query_test.go: Tests for the fluent query builder ---
+
+func TestQueryBuilder_Compile(t *testing.T) {
+	dao := NewDAO[User](nil, WithDialect(Postgres))
+
+	var users []User
+	endpoint, err := dao.Query().
+		Table("users").
+		Fields("id", "name").
+		Where(map[string]any{"age": Gte(18)}).
+		Where(Or{map[string]any{"name": Like("A%")}, map[string]any{"name": Like("B%")}}).
+		OrderByDesc("age").
+		OrderBy("id").
+		Limit(10).
+		Offset(20).
+		ToSelect(&users)
+	require.NoError(t, err)
+	assert.Equal(t, &users, endpoint.Model)
+	assert.Equal(t, []string{`ORDER BY "age" DESC, "id" ASC`, "LIMIT 10 OFFSET 20"}, endpoint.Appends)
+
+	query, args, err := endpoint.point2Sql(SQLite)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT `id`,`name` FROM `users` WHERE ((`age` >= ?)) AND (((`name` LIKE ?)) OR ((`name` LIKE ?))) ORDER BY \"age\" DESC, \"id\" ASC LIMIT 10 OFFSET 20", query)
+	assert.Equal(t, []any{18, "A%", "B%"}, args)
+
+	page, err := dao.Query().Where(map[string]any{"age": Gt(1)}).OrderBy("id").ToPage(&users, 3, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []SortKey{{Column: "id"}}, page.SortKeys)
+	assert.Equal(t, int32(3), page.PageNo)
+
+	get, err := dao.Query().ToGet(new(User))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LIMIT 1 OFFSET 0"}, get.Appends)
+
+	_, err = dao.Query().OrderBy("id desc").ToSelect(&users)
+	assert.Error(t, err)
+	_, err = dao.Query().Limit(1).ToDelete()
+	assert.EqualError(t, err, "order by, limit and offset are not supported for delete")
+}
+
+func (s *DAOTestSuite) TestQueryBuilder() {
+	ctx := context.Background()
+	var users []User
+	err := s.userDAO.Query().Where(map[string]any{"age": Gte(30)}).OrderByDesc("age").Limit(1).Select(ctx, &users)
+	s.Require().NoError(err)
+	s.Require().Len(users, 1)
+	s.Equal("Bob", users[0].Name)
+
+	user, err := s.userDAO.Query().OrderBy("id").First(ctx)
+	s.Require().NoError(err)
+	s.Equal("Alice", user.Name)
+	_, err = s.userDAO.Query().Where(map[string]any{"id": Eq(99)}).First(ctx)
+	s.ErrorIs(err, sql.ErrNoRows)
+
+	count, err := s.userDAO.Query().Where(map[string]any{"age": Gt(35)}).Count(ctx)
+	s.Require().NoError(err)
+	s.Equal(int64(1), count)
+
+	users = nil
+	total, err := s.userDAO.Query().OrderByDesc("id").Paginate(ctx, &users, 1, 1)
+	s.Require().NoError(err)
+	s.Equal(int64(2), total)
+	s.Equal("Bob", users[0].Name)
+
+	affected, err := s.userDAO.Query().Where(map[string]any{"name": Eq("Alice")}).Update(ctx, map[string]any{"age": 31})
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+
+	// 构建器的结果可以直接交给 endpoint 风格的 API
+	endpoint, err := s.userDAO.Query().Where(map[string]any{"age": Eq(31)}).ToDelete()
+	s.Require().NoError(err)
+	affected, err = s.userDAO.Delete(ctx, endpoint)
+	s.Require().NoError(err)
+	s.Equal(int64(1), affected)
+
+	affected, err = s.userDAO.Query().Delete(ctx)
+	s.EqualError(err, "empty conditions for delete")
+	s.Equal(int64(0), affected)
+}
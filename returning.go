@@ -0,0 +1,90 @@
+package db_dao
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// driverNamer 由 *sqlx.DB 和 *sqlx.Tx 实现
+type driverNamer interface {
+	DriverName() string
+}
+
+// executorUnwrapper 由包装其他执行器的执行器实现，以便识别被包装执行器的驱动
+type executorUnwrapper interface {
+	Unwrap() Executor
+}
+
+// driverNameOf 返回执行器的驱动名称，必要时沿 Unwrap 逐层查找；无法获取时返回空字符串。
+func driverNameOf(db Executor) string {
+	for db != nil {
+		if dn, ok := db.(driverNamer); ok {
+			return dn.DriverName()
+		}
+		u, ok := db.(executorUnwrapper)
+		if !ok {
+			break
+		}
+		db = u.Unwrap()
+	}
+	return ""
+}
+
+// batchInsertIDs 根据 LastInsertId 推算批量插入中每一行的自增 ID。
+// MySQL 返回本批第一行的 ID，SQLite 返回最后一行的 ID；两者在单条多行 INSERT 中都是连续分配的。
+func batchInsertIDs(dialect Dialect, lastID int64, n int) []int64 {
+	first := lastID - int64(n) + 1
+	if dialect.Name() == MySQL.Name() {
+		first = lastID
+	}
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = first + int64(i)
+	}
+	return ids
+}
+
+// resizeModels 确保接收批量结果的切片长度与行数一致：空切片会被扩充为 n 个零值。
+func resizeModels[T any](models *[]T, n int) error {
+	if models == nil {
+		return errors.New("nil model")
+	}
+	switch len(*models) {
+	case n:
+	case 0:
+		*models = make([]T, n)
+	default:
+		return fmt.Errorf("model length %d does not match %d rows", len(*models), n)
+	}
+	return nil
+}
+
+// setInsertID 将 LastInsertId 写入 column 对应的结构体字段。
+func setInsertID(m *reflectx.Mapper, v reflect.Value, column string, id int64) error {
+	fi := m.TypeMap(v.Type()).GetByPath(column)
+	if fi == nil {
+		return fmt.Errorf("missing destination name %s in %v", column, v.Type())
+	}
+	fv := reflectx.FieldByIndexes(v, fi.Index)
+	if fv.Kind() == reflect.Ptr {
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fv.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(uint64(id))
+	default:
+		if fv.Addr().Type().Implements(scannerType) {
+			return fv.Addr().Interface().(sql.Scanner).Scan(id)
+		}
+		return fmt.Errorf("cannot assign insert id to %s of type %v", column, fv.Type())
+	}
+	return nil
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
@@ -0,0 +1,169 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// txState 同一个数据库事务中各层 DAO 共享的嵌套状态
+type txState struct {
+	mu     sync.Mutex
+	levels []*txLevel // levels 由外到内尚未结束的层
+	closed bool       // closed 最外层事务已结束
+}
+
+// txLevel BeginTx 返回的 DAO 所在的层：0 为最外层事务，n 为第 n 个保存点
+type txLevel struct {
+	state *txState
+	depth int
+	name  string // name 保存点名，最外层为空
+	done  bool   // done 本层已结束，包括随外层一起结束
+}
+
+// newTxLevel 返回新事务的最外层
+func newTxLevel() *txLevel {
+	s := &txState{}
+	level := &txLevel{state: s}
+	s.levels = []*txLevel{level}
+	return level
+}
+
+// savepointSeq 保存点序号。同一个 *sqlx.Tx 可能被多个互不相关的 Begin / BeginTx 嵌套，
+// 按深度命名会重名，而 MySQL 会用同名的新保存点替换旧的，因此名字在进程内唯一
+var savepointSeq atomic.Uint64
+
+// newSavepointName 返回一个未使用过的保存点名
+func newSavepointName() string {
+	return fmt.Sprintf("db_dao_sp_%d", savepointSeq.Add(1))
+}
+
+// push 在最内层之上打开一层；parent 已经结束时返回 sql.ErrTxDone
+func (s *txState) push(parent *txLevel) (*txLevel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || (parent != nil && parent.done) {
+		return nil, sql.ErrTxDone
+	}
+	depth := 1
+	if n := len(s.levels); n > 0 {
+		depth = s.levels[n-1].depth + 1
+	}
+	level := &txLevel{state: s, depth: depth}
+	s.levels = append(s.levels, level)
+	return level, nil
+}
+
+// end 结束本层及其内层；本层已经结束 (包括随外层一起结束) 时返回 false。
+// 按身份而不是深度查找本层，已结束的层不会与之后在同一深度打开的层混淆
+func (l *txLevel) end() bool {
+	s := l.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l.done {
+		return false
+	}
+	i := slices.Index(s.levels, l)
+	if i < 0 {
+		return false
+	}
+	for _, inner := range s.levels[i:] {
+		inner.done = true
+	}
+	clear(s.levels[i:])
+	s.levels = s.levels[:i]
+	if l.depth == 0 {
+		s.closed = true
+	}
+	return true
+}
+
+// beginLevel 在 tx 中 parent 所在的事务里创建保存点，返回新的一层；parent 为 nil 时 tx 由调用方直接持有
+func beginLevel(ctx context.Context, tx *sqlx.Tx, dialect Dialect, parent *txLevel) (*txLevel, error) {
+	state := &txState{}
+	if parent != nil {
+		state = parent.state
+	}
+	level, err := state.push(parent)
+	if err != nil {
+		return nil, err
+	}
+	level.name = newSavepointName()
+	if _, err := tx.ExecContext(ctx, dialect.Savepoint(level.name)); err != nil {
+		level.end()
+		return nil, err
+	}
+	return level, nil
+}
+
+// endLevel 结束 level：最外层 (或 nil) 直接提交或回滚 tx，内层释放或回滚到保存点
+func endLevel(ctx context.Context, tx *sqlx.Tx, dialect Dialect, level *txLevel, rollback bool) error {
+	if level == nil || level.depth == 0 {
+		if level != nil {
+			level.end()
+		}
+		if rollback {
+			return tx.Rollback()
+		}
+		return tx.Commit()
+	}
+	if !level.end() {
+		return sql.ErrTxDone
+	}
+	name := level.name
+	var statements []string
+	if rollback {
+		statements = append(statements, dialect.RollbackToSavepoint(name))
+	}
+	// 回滚到保存点后保存点仍然存在，同样需要释放
+	if release := dialect.ReleaseSavepoint(name); release != "" {
+		statements = append(statements, release)
+	}
+	for _, query := range statements {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endTx 结束 d 所在的事务层
+func (d *DAO[T]) endTx(ctx context.Context, rollback bool) error {
+	tx, ok := d.db.(*sqlx.Tx)
+	if !ok {
+		return sql.ErrTxDone
+	}
+	return endLevel(ctx, tx, d.cfg.dialect, d.txn, rollback)
+}
+
+// txEnder 由事务 DAO 与 *sqlx.Tx 实现
+type txEnder interface {
+	Commit() error
+	Rollback() error
+}
+
+// finishTx 执行 fn：返回 nil 时提交，返回错误、panic 或 runtime.Goexit 时回滚
+func finishTx(tx txEnder, fn func() error) error {
+	finished := false
+	defer func() {
+		// fn panic 时同样在此回滚，之后 panic 继续向上传播
+		if !finished {
+			_ = tx.Rollback()
+		}
+	}()
+	err := fn()
+	finished = true
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,182 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- savepoint_test.go: Tests for nested transactions ---
+
+func TestSavepointStatements(t *testing.T) {
+	assert.Equal(t, "SAVEPOINT db_dao_sp_1", Postgres.Savepoint("db_dao_sp_1"))
+	assert.Equal(t, "RELEASE SAVEPOINT db_dao_sp_1", MySQL.ReleaseSavepoint("db_dao_sp_1"))
+	assert.Equal(t, "ROLLBACK TO SAVEPOINT db_dao_sp_2", SQLite.RollbackToSavepoint("db_dao_sp_2"))
+	assert.Equal(t, "SAVE TRANSACTION db_dao_sp_1", SQLServer.Savepoint("db_dao_sp_1"))
+	assert.Equal(t, "", SQLServer.ReleaseSavepoint("db_dao_sp_1"))
+	assert.Equal(t, "ROLLBACK TRANSACTION db_dao_sp_1", SQLServer.RollbackToSavepoint("db_dao_sp_1"))
+}
+
+func TestNestedTx(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[User](newSQLiteDB(t, createUsersTable, seedUsers))
+
+	outer, err := dao.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = outer.UpdateByID(ctx, 1, map[string]any{"age": 31})
+	require.NoError(t, err)
+
+	// 内层回滚只撤销保存点之后的修改
+	inner, err := outer.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = inner.UpdateByID(ctx, 1, map[string]any{"age": 99})
+	require.NoError(t, err)
+	require.NoError(t, inner.Rollback())
+	assert.Equal(t, 31, userAge(t, outer, 1))
+	assert.ErrorIs(t, inner.Commit(), sql.ErrTxDone)
+
+	// 内层提交只释放保存点，修改随外层提交
+	inner, err = outer.BeginTx(ctx)
+	require.NoError(t, err)
+	deeper, err := inner.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = deeper.UpdateByID(ctx, 2, map[string]any{"age": 41})
+	require.NoError(t, err)
+	require.NoError(t, deeper.Commit())
+	require.NoError(t, inner.Commit())
+
+	require.NoError(t, outer.Commit())
+	assert.ErrorIs(t, outer.Commit(), sql.ErrTxDone)
+	_, err = outer.BeginTx(ctx)
+	assert.ErrorIs(t, err, sql.ErrTxDone)
+	assert.Equal(t, 31, userAge(t, dao, 1))
+	assert.Equal(t, 41, userAge(t, dao, 2))
+}
+
+func TestNestedTx_OuterEndsInner(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[User](newSQLiteDB(t, createUsersTable, seedUsers))
+
+	outer, err := dao.BeginTx(ctx)
+	require.NoError(t, err)
+	inner, err := outer.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = inner.UpdateByID(ctx, 1, map[string]any{"age": 99})
+	require.NoError(t, err)
+	require.NoError(t, inner.Commit())
+
+	// 外层回滚同样撤销已提交的内层
+	require.NoError(t, outer.Rollback())
+	assert.Equal(t, 30, userAge(t, dao, 1))
+
+	outer, err = dao.BeginTx(ctx)
+	require.NoError(t, err)
+	first, err := outer.BeginTx(ctx)
+	require.NoError(t, err)
+	second, err := first.BeginTx(ctx)
+	require.NoError(t, err)
+	// 结束外面的一层时，里面的层随之结束
+	require.NoError(t, first.Rollback())
+	assert.ErrorIs(t, second.Commit(), sql.ErrTxDone)
+	require.NoError(t, outer.Commit())
+}
+
+func TestNestedTx_StaleLevel(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[User](newSQLiteDB(t, createUsersTable, seedUsers))
+
+	outer, err := dao.BeginTx(ctx)
+	require.NoError(t, err)
+	a, err := outer.BeginTx(ctx)
+	require.NoError(t, err)
+	b, err := a.BeginTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, a.Rollback())
+	_, err = a.BeginTx(ctx)
+	assert.ErrorIs(t, err, sql.ErrTxDone)
+
+	// 之后在同一深度打开的层与已结束的 b 互不影响
+	c, err := outer.BeginTx(ctx)
+	require.NoError(t, err)
+	d, err := c.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = d.UpdateByID(ctx, 1, map[string]any{"age": 31})
+	require.NoError(t, err)
+	assert.ErrorIs(t, b.Commit(), sql.ErrTxDone)
+	assert.ErrorIs(t, b.Rollback(), sql.ErrTxDone)
+	require.NoError(t, d.Commit())
+	require.NoError(t, c.Commit())
+	require.NoError(t, outer.Commit())
+	assert.Equal(t, 31, userAge(t, dao, 1))
+}
+
+func TestNestedTx_WithTx(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[User](newSQLiteDB(t, createUsersTable, seedUsers))
+	errBoom := errors.New("boom")
+
+	err := dao.WithTx(ctx, nil, func(tx IDAO[User]) error {
+		if _, err := tx.UpdateByID(ctx, 1, map[string]any{"age": 31}); err != nil {
+			return err
+		}
+		// 嵌套的 WithTx 失败时只回滚自己的修改
+		err := tx.WithTx(ctx, nil, func(nested IDAO[User]) error {
+			if _, err := nested.UpdateByID(ctx, 2, map[string]any{"age": 99}); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		assert.ErrorIs(t, err, errBoom)
+
+		assert.Panics(t, func() {
+			_ = tx.WithTx(ctx, nil, func(nested IDAO[User]) error {
+				_, _ = nested.UpdateByID(ctx, 2, map[string]any{"age": 98})
+				panic("boom")
+			})
+		})
+
+		return tx.WithTx(ctx, nil, func(nested IDAO[User]) error {
+			_, err := nested.UpdateByID(ctx, 2, map[string]any{"age": 41})
+			return err
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 31, userAge(t, dao, 1))
+	assert.Equal(t, 41, userAge(t, dao, 2))
+}
+
+func TestNestedTx_FromRawTx(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, createUsersTable, seedUsers)
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+
+	// 直接包装 *sqlx.Tx 的 DAO 也可以嵌套，提交由 tx 的持有者负责
+	dao := NewDAO[User](tx)
+	inner, err := dao.WithTrashed().BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = inner.UpdateByID(ctx, 1, map[string]any{"age": 99})
+	require.NoError(t, err)
+	require.NoError(t, inner.Rollback())
+	assert.Equal(t, 30, userAge(t, dao, 1))
+
+	// 同一个 tx 上互不相关的嵌套使用不同的保存点名，内层释放后外层仍可回滚到自己的保存点
+	first, err := Begin(ctx, tx, nil)
+	require.NoError(t, err)
+	_, err = Use[User](first).UpdateByID(ctx, 1, map[string]any{"age": 98})
+	require.NoError(t, err)
+	second, err := NewDAO[User](tx).BeginTx(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.level.name, second.(*DAO[User]).txn.name)
+	_, err = second.UpdateByID(ctx, 2, map[string]any{"age": 97})
+	require.NoError(t, err)
+	require.NoError(t, second.Commit())
+	require.NoError(t, first.Rollback())
+	assert.Equal(t, 30, userAge(t, dao, 1))
+	assert.Equal(t, 40, userAge(t, dao, 2))
+	require.NoError(t, tx.Commit())
+}
@@ -0,0 +1,128 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSoftDeleteDisabled is returned by OnlyTrashed queries and Restore when the DAO has no soft-delete column.
+var ErrSoftDeleteDisabled = errors.New("soft delete is not enabled")
+
+// trashedScope 决定查询是否包含已软删除的行
+type trashedScope int
+
+const (
+	withoutTrashed trashedScope = iota // 默认：排除已删除的行
+	withTrashed                        // 包含已删除的行
+	onlyTrashed                        // 只查询已删除的行
+)
+
+// WithSoftDelete enables soft deletion using column, which must be a nullable timestamp.
+// Soft deletion is also enabled when a field of T is tagged `dao:"soft_delete"`.
+func WithSoftDelete(column string) Option {
+	return func(c *config) {
+		c.softDelete = column
+	}
+}
+
+// softDeleteColumn 返回软删除列，未启用时返回空字符串。WithSoftDelete 优先于 T 的标签。
+func (d *DAO[T]) softDeleteColumn() string {
+	if d.cfg.softDelete != "" {
+		return d.cfg.softDelete
+	}
+	return d.meta().taggedColumn("soft_delete")
+}
+
+// scoped 按 scope 为查询条件追加软删除过滤，软删除列以 qualifier 限定
+func (d *DAO[T]) scoped(conditions Condition, qualifier string) (Condition, error) {
+	column := d.softDeleteColumn()
+	if column == "" {
+		if d.scope == onlyTrashed {
+			return nil, ErrSoftDeleteDisabled
+		}
+		return conditions, nil
+	}
+	column = qualify(qualifier, column)
+	switch d.scope {
+	case withTrashed:
+		return conditions, nil
+	case onlyTrashed:
+		return andCondition(conditions, map[string]any{column: IsNotNull()}), nil
+	}
+	return andCondition(conditions, map[string]any{column: IsNull()}), nil
+}
+
+// andCondition 以 AND 连接两个条件，conditions 为空时直接返回 extra
+func andCondition(conditions, extra Condition) Condition {
+	if conditions == nil {
+		return extra
+	}
+	if m, ok := conditions.(map[string]any); ok && len(m) == 0 {
+		return extra
+	}
+	return And{conditions, extra}
+}
+
+// requireConditions 防止 Delete / Restore 在没有条件时影响整张表
+func requireConditions(dialect Dialect, conditions Condition, msg string) error {
+	where, _, err := buildWhereClause(dialect, conditions)
+	if err != nil {
+		return err
+	}
+	if where == "" {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// WithTrashed returns a DAO whose queries also include soft-deleted rows.
+func (d *DAO[T]) WithTrashed() IDAO[T] {
+	return &DAO[T]{db: d.db, cfg: d.cfg, scope: withTrashed, txn: d.txn}
+}
+
+// OnlyTrashed returns a DAO whose queries only include soft-deleted rows.
+func (d *DAO[T]) OnlyTrashed() IDAO[T] {
+	return &DAO[T]{db: d.db, cfg: d.cfg, scope: onlyTrashed, txn: d.txn}
+}
+
+// Restore clears the soft-delete column of the deleted rows matching endpoint.Conditions.
+func (d *DAO[T]) Restore(ctx context.Context, endpoint DeleteEndPoint[T]) (int64, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{conditions: endpoint.Conditions}); err != nil {
+		return 0, err
+	}
+	column := d.softDeleteColumn()
+	if column == "" {
+		return 0, ErrSoftDeleteDisabled
+	}
+	if err := requireConditions(d.cfg.dialect, endpoint.Conditions, "empty conditions for restore"); err != nil {
+		return 0, err
+	}
+	return d.update(ctx, "Restore", UpdateEndPoint[T]{
+		Table:      endpoint.Table,
+		Rows:       d.stampUpdate(map[string]any{column: nil}, d.cfg.now()),
+		Conditions: andCondition(endpoint.Conditions, map[string]any{column: IsNotNull()}),
+	})
+}
+
+// ForceDelete physically deletes the rows matching endpoint.Conditions, whether soft-deleted or not.
+func (d *DAO[T]) ForceDelete(ctx context.Context, endpoint DeleteEndPoint[T]) (int64, error) {
+	endpoint.Table = d.table(endpoint.Table)
+	if err := d.checkColumns(columnRefs{conditions: endpoint.Conditions}); err != nil {
+		return 0, err
+	}
+	return d.delete(ctx, "ForceDelete", endpoint)
+}
+
+// softDelete 将匹配且未删除的行标记为已删除并刷新更新时间，保留已删除行原有的删除时间
+func (d *DAO[T]) softDelete(ctx context.Context, column string, endpoint DeleteEndPoint[T]) (int64, error) {
+	if err := requireConditions(d.cfg.dialect, endpoint.Conditions, "empty conditions for delete"); err != nil {
+		return 0, err
+	}
+	now := d.cfg.now()
+	return d.update(ctx, "Delete", UpdateEndPoint[T]{
+		Table:      endpoint.Table,
+		Rows:       d.stampUpdate(map[string]any{column: now}, now),
+		Conditions: andCondition(endpoint.Conditions, map[string]any{column: IsNull()}),
+	})
+}
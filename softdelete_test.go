@@ -0,0 +1,172 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- softdelete_test.go: Tests for soft deletion ---
+
+type post struct {
+	ID        int64        `db:"id"`
+	Title     string       `db:"title"`
+	DeletedAt sql.NullTime `db:"deleted_at" dao:"soft_delete"`
+}
+
+var postsSchema = []string{
+	`CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, deleted_at DATETIME)`,
+	`INSERT INTO posts (id, title) VALUES (1, 'a'), (2, 'b'), (3, 'c')`,
+}
+
+func postIDs(posts []post) []int64 {
+	ids := make([]int64, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, postsSchema...)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	dao := NewDAO[post](db, WithClock(func() time.Time { return now }))
+
+	affected, err := dao.Delete(ctx, DeleteEndPoint[post]{Table: "posts", Conditions: map[string]any{"id": In(1, 2)}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+
+	// 再次删除不会覆盖原有的删除时间
+	affected, err = dao.Delete(ctx, DeleteEndPoint[post]{Table: "posts", Conditions: map[string]any{"id": Eq(1)}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	var posts []post
+	require.NoError(t, dao.Select(ctx, SelectEndPoint[post]{Model: &posts, Table: "posts"}))
+	assert.Equal(t, []int64{3}, postIDs(posts))
+
+	var p post
+	err = dao.Get(ctx, GetEndPoint[post]{Model: &p, Table: "posts", Conditions: map[string]any{"id": Eq(1)}})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	total, err := dao.Paginate(ctx, PageEndPoint[post]{Model: &posts, Table: "posts", PageNo: 1, PageSize: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	_, err = dao.PaginateCursor(ctx, CursorPageEndPoint[post]{Model: &posts, Table: "posts", SortKeys: []SortKey{{Column: "id"}}, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, postIDs(posts))
+
+	posts = nil
+	require.NoError(t, dao.WithTrashed().Select(ctx, SelectEndPoint[post]{Model: &posts, Table: "posts", Appends: []string{"ORDER BY id"}}))
+	assert.Equal(t, []int64{1, 2, 3}, postIDs(posts))
+
+	posts = nil
+	require.NoError(t, dao.OnlyTrashed().Select(ctx, SelectEndPoint[post]{Model: &posts, Table: "posts", Appends: []string{"ORDER BY id"}}))
+	assert.Equal(t, []int64{1, 2}, postIDs(posts))
+	assert.True(t, posts[0].DeletedAt.Valid)
+	assert.True(t, now.Equal(posts[0].DeletedAt.Time))
+
+	affected, err = dao.Restore(ctx, DeleteEndPoint[post]{Table: "posts", Conditions: map[string]any{"id": Eq(2)}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	affected, err = dao.ForceDelete(ctx, DeleteEndPoint[post]{Table: "posts", Conditions: map[string]any{"id": Eq(1)}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	posts = nil
+	require.NoError(t, dao.WithTrashed().Select(ctx, SelectEndPoint[post]{Model: &posts, Table: "posts", Appends: []string{"ORDER BY id"}}))
+	assert.Equal(t, []int64{2, 3}, postIDs(posts))
+	assert.False(t, posts[0].DeletedAt.Valid)
+}
+
+func TestSoftDelete_EmptyConditions(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[post](newSQLiteDB(t, postsSchema...))
+
+	_, err := dao.Delete(ctx, DeleteEndPoint[post]{Table: "posts"})
+	assert.EqualError(t, err, "empty conditions for delete")
+	_, err = dao.Restore(ctx, DeleteEndPoint[post]{Table: "posts", Conditions: map[string]any{}})
+	assert.EqualError(t, err, "empty conditions for restore")
+}
+
+func TestSoftDelete_Option(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, postsSchema...)
+	// plainPost 没有 soft_delete 标签，通过选项启用
+	type plainPost struct {
+		ID    int64  `db:"id"`
+		Title string `db:"title"`
+	}
+	dao := NewDAO[plainPost](db, WithSoftDelete("deleted_at"))
+
+	_, err := dao.Delete(ctx, DeleteEndPoint[plainPost]{Table: "posts", Conditions: map[string]any{"id": Eq(1)}})
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM posts"))
+	assert.Equal(t, 3, count, "row must still exist")
+
+	txDAO, err := dao.OnlyTrashed().BeginTx(ctx)
+	require.NoError(t, err)
+	var posts []plainPost
+	require.NoError(t, txDAO.Select(ctx, SelectEndPoint[plainPost]{Model: &posts, Table: "posts", Fields: []string{"id", "title"}}))
+	require.NoError(t, txDAO.Commit())
+	require.Len(t, posts, 1)
+	assert.Equal(t, int64(1), posts[0].ID)
+}
+
+func TestSoftDelete_Disabled(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, postsSchema...)
+	dao := NewDAO[User](db)
+
+	var users []User
+	err := dao.OnlyTrashed().Select(ctx, SelectEndPoint[User]{Model: &users, Table: "posts"})
+	assert.ErrorIs(t, err, ErrSoftDeleteDisabled)
+	_, err = dao.Restore(ctx, DeleteEndPoint[User]{Table: "posts", Conditions: map[string]any{"id": Eq(1)}})
+	assert.ErrorIs(t, err, ErrSoftDeleteDisabled)
+
+	affected, err := dao.Delete(ctx, DeleteEndPoint[User]{Table: "posts", Conditions: map[string]any{"id": Eq(1)}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	var count int
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM posts"))
+	assert.Equal(t, 2, count)
+}
+
+type note struct {
+	ID        int64        `db:"id"`
+	UpdatedAt time.Time    `db:"updated_at" dao:"updated_at"`
+	DeletedAt sql.NullTime `db:"deleted_at" dao:"soft_delete"`
+}
+
+func TestSoftDelete_Timestamps(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t,
+		`CREATE TABLE notes (id INTEGER PRIMARY KEY, updated_at DATETIME, deleted_at DATETIME)`,
+		`INSERT INTO notes (id, updated_at) VALUES (1, '2020-01-01 00:00:00')`,
+	)
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	dao := NewDAO[note](db, WithClock(clock.now))
+
+	// 软删除与恢复都会刷新更新时间，删除时间与更新时间相同
+	_, err := dao.Delete(ctx, DeleteEndPoint[note]{Conditions: map[string]any{"id": Eq(1)}})
+	require.NoError(t, err)
+	var n note
+	require.NoError(t, dao.WithTrashed().Get(ctx, GetEndPoint[note]{Model: &n, Conditions: map[string]any{"id": Eq(1)}}))
+	assert.True(t, n.UpdatedAt.Equal(clock.t))
+	assert.True(t, n.DeletedAt.Time.Equal(clock.t))
+
+	_, err = dao.Restore(ctx, DeleteEndPoint[note]{Conditions: map[string]any{"id": Eq(1)}})
+	require.NoError(t, err)
+	require.NoError(t, dao.Get(ctx, GetEndPoint[note]{Model: &n, Conditions: map[string]any{"id": Eq(1)}}))
+	assert.True(t, n.UpdatedAt.Equal(clock.t))
+	assert.False(t, n.DeletedAt.Valid)
+}
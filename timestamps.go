@@ -0,0 +1,84 @@
+package db_dao
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// WithTimestamps names the columns filled automatically on insert (createdAt, updatedAt)
+// and on update (updatedAt). An empty name disables that column. Without this option the
+// fields of T tagged `dao:"created_at"` / `dao:"updated_at"` are used.
+func WithTimestamps(createdAt, updatedAt string) Option {
+	return func(c *config) {
+		c.createdAt, c.updatedAt = &createdAt, &updatedAt
+	}
+}
+
+// WithClock sets the clock used for automatic timestamps and soft deletion. Defaults to time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(c *config) {
+		c.now = now
+	}
+}
+
+// timestampColumns 返回自动时间戳列，未启用的列为空字符串
+func (d *DAO[T]) timestampColumns() (createdAt, updatedAt string) {
+	if d.cfg.createdAt != nil {
+		return *d.cfg.createdAt, *d.cfg.updatedAt
+	}
+	meta := d.meta()
+	return meta.taggedColumn("created_at"), meta.taggedColumn("updated_at")
+}
+
+// isUnset 判断调用方是否未设置该值 (缺失、nil 或零值)
+func isUnset(row map[string]any, column string) bool {
+	v, ok := row[column]
+	if !ok || v == nil {
+		return true
+	}
+	return reflect.ValueOf(v).IsZero()
+}
+
+// stampRow 为未设置的列填充 now，需要修改时返回 row 的副本。空行保持不变，交由 endpoint 报错。
+func stampRow(row map[string]any, now time.Time, columns ...string) map[string]any {
+	if len(row) == 0 {
+		return row
+	}
+	var out map[string]any
+	for _, column := range columns {
+		if column == "" || !isUnset(row, column) {
+			continue
+		}
+		if out == nil {
+			out = maps.Clone(row)
+		}
+		out[column] = now
+	}
+	if out == nil {
+		return row
+	}
+	return out
+}
+
+// stampRows 对每一行调用 stampRow，所有行使用同一时间
+func stampRows(rows []map[string]any, now time.Time, columns ...string) []map[string]any {
+	out := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		out[i] = stampRow(row, now, columns...)
+	}
+	return out
+}
+
+// upsertUpdateColumns 冲突更新时不覆盖创建时间，并确保刷新更新时间
+func upsertUpdateColumns(columns, conflict, update []string, createdAt, updatedAt string) []string {
+	if len(update) == 0 {
+		excluded := append(slices.Clone(conflict), createdAt)
+		return excludeColumns(columns, excluded)
+	}
+	if updatedAt != "" && !slices.Contains(update, updatedAt) {
+		return append(slices.Clone(update), updatedAt)
+	}
+	return update
+}
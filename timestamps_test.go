@@ -0,0 +1,112 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- timestamps_test.go: Tests for automatic created_at / updated_at ---
+
+type article struct {
+	ID        int64     `db:"id"`
+	Slug      string    `db:"slug"`
+	Title     string    `db:"title"`
+	CreatedAt time.Time `db:"created_at" dao:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" dao:"updated_at"`
+}
+
+// fakeClock 每次调用前进一小时
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time {
+	c.t = c.t.Add(time.Hour)
+	return c.t
+}
+
+const createArticlesTable = `CREATE TABLE articles (id INTEGER PRIMARY KEY, slug TEXT UNIQUE, title TEXT, created_at DATETIME, updated_at DATETIME)`
+
+func getArticle(t *testing.T, dao IDAO[article], slug string) article {
+	var a article
+	require.NoError(t, dao.Get(context.Background(), GetEndPoint[article]{Model: &a, Table: "articles", Conditions: map[string]any{"slug": Eq(slug)}}))
+	return a
+}
+
+func TestStampRow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	explicit := now.Add(-time.Hour)
+	row := map[string]any{"name": "a", "updated_at": explicit, "created_at": time.Time{}}
+
+	out := stampRow(row, now, "created_at", "updated_at")
+	assert.Equal(t, map[string]any{"name": "a", "updated_at": explicit, "created_at": now}, out)
+	assert.Equal(t, time.Time{}, row["created_at"], "caller's map must not change")
+
+	assert.Empty(t, stampRow(map[string]any{}, now, "created_at"))
+	assert.Equal(t, []string{"title", "updated_at"}, upsertUpdateColumns([]string{"created_at", "slug", "title", "updated_at"}, []string{"slug"}, nil, "created_at", "updated_at"))
+	assert.Equal(t, []string{"title", "updated_at"}, upsertUpdateColumns(nil, []string{"slug"}, []string{"title"}, "created_at", "updated_at"))
+}
+
+func TestTimestamps(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	dao := NewDAO[article](newSQLiteDB(t, createArticlesTable), WithClock(clock.now))
+
+	_, err := dao.Insert(ctx, InsertEndpoint[article]{Table: "articles", Rows: map[string]any{"slug": "a", "title": "A"}})
+	require.NoError(t, err)
+	a := getArticle(t, dao, "a")
+	assert.Equal(t, clock.t, a.CreatedAt.UTC())
+	assert.Equal(t, clock.t, a.UpdatedAt.UTC())
+	created := a.CreatedAt
+
+	_, err = dao.Update(ctx, UpdateEndPoint[article]{Table: "articles", Rows: map[string]any{"title": "A2"}, Conditions: map[string]any{"slug": Eq("a")}})
+	require.NoError(t, err)
+	a = getArticle(t, dao, "a")
+	assert.Equal(t, created, a.CreatedAt)
+	assert.Equal(t, clock.t, a.UpdatedAt.UTC())
+
+	// 调用方显式设置的值不会被覆盖
+	explicit := time.Date(2020, 5, 5, 0, 0, 0, 0, time.UTC)
+	_, err = dao.InsertModel(ctx, "articles", &article{Slug: "b", Title: "B", CreatedAt: explicit})
+	require.NoError(t, err)
+	b := getArticle(t, dao, "b")
+	assert.Equal(t, explicit, b.CreatedAt.UTC())
+	assert.Equal(t, clock.t, b.UpdatedAt.UTC())
+
+	// 冲突更新时保留创建时间、刷新更新时间
+	_, err = dao.BatchUpsert(ctx, BatchUpsertEndpoint[article]{
+		Table:           "articles",
+		Rows:            []map[string]any{{"slug": "a", "title": "A3"}, {"slug": "c", "title": "C"}},
+		ConflictColumns: []string{"slug"},
+	})
+	require.NoError(t, err)
+	a = getArticle(t, dao, "a")
+	assert.Equal(t, "A3", a.Title)
+	assert.Equal(t, created, a.CreatedAt)
+	assert.Equal(t, clock.t, a.UpdatedAt.UTC())
+	c := getArticle(t, dao, "c")
+	assert.Equal(t, clock.t, c.CreatedAt.UTC())
+}
+
+func TestTimestamps_Option(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	type plainArticle struct {
+		Slug      string       `db:"slug"`
+		CreatedAt time.Time    `db:"created_at"`
+		UpdatedAt sql.NullTime `db:"updated_at"`
+	}
+	db := newSQLiteDB(t, createArticlesTable)
+	dao := NewDAO[plainArticle](db, WithTimestamps("created_at", ""), WithClock(func() time.Time { return now }))
+
+	_, err := dao.Upsert(ctx, UpsertEndpoint[plainArticle]{Table: "articles", Rows: map[string]any{"slug": "a"}, ConflictColumns: []string{"slug"}, DoNothing: true})
+	require.NoError(t, err)
+
+	var got plainArticle
+	require.NoError(t, db.Get(&got, "SELECT slug, created_at, updated_at FROM articles"))
+	assert.Equal(t, now, got.CreatedAt.UTC())
+	assert.False(t, got.UpdatedAt.Valid)
+}
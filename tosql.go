@@ -0,0 +1,246 @@
+package db_dao
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// explainHeader 标记 Explain 的输出只用于阅读，防止被复制后直接执行
+const explainHeader = "/* DEBUG ONLY - args interpolated, do not execute */ "
+
+// statementBuilder 由所有 endpoint 实现，返回使用 `?` 占位符的 SQL
+type statementBuilder interface {
+	buildStatement(dialect Dialect) (string, []any, error)
+}
+
+// dialectOrDefault nil 时回退到 SQLite (`?` 占位符，LIMIT/OFFSET)，只用于生成 SQL
+func dialectOrDefault(dialect Dialect) Dialect {
+	if dialect == nil {
+		return SQLite
+	}
+	return dialect
+}
+
+func toSQL(s statementBuilder, dialect Dialect) (string, []any, error) {
+	dialect = dialectOrDefault(dialect)
+	query, args, err := s.buildStatement(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	return dialect.Rebind(query), args, nil
+}
+
+func explain(s statementBuilder, dialect Dialect) (string, error) {
+	dialect = dialectOrDefault(dialect)
+	query, args, err := s.buildStatement(dialect)
+	if err != nil {
+		return "", err
+	}
+	return interpolate(dialect, query, args)
+}
+
+// interpolate 将 args 依次替换 query 中的 `?`，跳过引号内的内容
+func interpolate(dialect Dialect, query string, args []any) (string, error) {
+	var b strings.Builder
+	b.WriteString(explainHeader)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			if n >= len(args) {
+				return "", fmt.Errorf("query has more placeholders than the %d args", len(args))
+			}
+			b.WriteString(literal(dialect, args[n]))
+			n++
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if n != len(args) {
+		return "", fmt.Errorf("query has %d placeholders, got %d args", n, len(args))
+	}
+	return b.String(), nil
+}
+
+// literal 将参数渲染为近似的 SQL 字面量，只用于阅读
+func literal(dialect Dialect, v any) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return quoteLiteral(fmt.Sprintf("<%v>", err))
+		}
+		v = value
+	}
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case redacted:
+		return quoteLiteral(redactedText)
+	case string:
+		return quoteLiteral(v)
+	case []byte:
+		return "x'" + hex.EncodeToString(v) + "'"
+	case bool:
+		return dialect.BoolLiteral(v)
+	case time.Time:
+		return quoteLiteral(v.Format(time.RFC3339Nano))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	}
+	return quoteLiteral(fmt.Sprint(v))
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (s GetEndPoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+// ToSQL returns the query and args the endpoint compiles to, with placeholders rebound for dialect
+// (nil means `?` placeholders). DAO behaviour such as table inference and soft-delete scopes is not applied.
+func (s GetEndPoint[T]) ToSQL(dialect Dialect) (string, []any, error) { return toSQL(s, dialect) }
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s GetEndPoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s SelectEndPoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+// ToSQL returns the query and args the endpoint compiles to, with placeholders rebound for dialect.
+func (s SelectEndPoint[T]) ToSQL(dialect Dialect) (string, []any, error) { return toSQL(s, dialect) }
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s SelectEndPoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s PageEndPoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2pageSql(dialect)
+}
+
+// ToSQL returns the page query and args, with placeholders rebound for dialect.
+// The COUNT query run by Paginate is returned by ToCountSQL.
+func (s PageEndPoint[T]) ToSQL(dialect Dialect) (string, []any, error) { return toSQL(s, dialect) }
+
+// ToCountSQL returns the COUNT(*) query and args Paginate runs before the page query.
+func (s PageEndPoint[T]) ToCountSQL(dialect Dialect) (string, []any, error) {
+	return toSQL(pageCount[T]{s}, dialect)
+}
+
+// Explain renders the page query with args interpolated, for logs and golden tests only.
+func (s PageEndPoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+// pageCount 将 PageEndPoint 的 COUNT 查询适配为 statementBuilder
+type pageCount[T any] struct{ PageEndPoint[T] }
+
+func (s pageCount[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+func (s CursorPageEndPoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect, nil, false)
+}
+
+// ToSQL returns the query and args of the first page, with placeholders rebound for dialect.
+// Cursor is ignored: decoding it requires the DAO's cursor secret.
+func (s CursorPageEndPoint[T]) ToSQL(dialect Dialect) (string, []any, error) {
+	return toSQL(s, dialect)
+}
+
+// Explain renders the first-page query with args interpolated, for logs and golden tests only.
+func (s CursorPageEndPoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s UpdateEndPoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	query, rowsArgs, conditionsArgs, err := s.point2Sql(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	// Use explicit new slice to avoid mutating rowsArgs when it has spare capacity.
+	args := make([]any, 0, len(rowsArgs)+len(conditionsArgs))
+	args = append(args, rowsArgs...)
+	args = append(args, conditionsArgs...)
+	return query, args, nil
+}
+
+// ToSQL returns the query and args the endpoint compiles to, with placeholders rebound for dialect.
+// Versioning and automatic timestamps are applied by DAO.Update, not here.
+func (s UpdateEndPoint[T]) ToSQL(dialect Dialect) (string, []any, error) { return toSQL(s, dialect) }
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s UpdateEndPoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s InsertEndpoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+// ToSQL returns the query and args the endpoint compiles to, with placeholders rebound for dialect.
+func (s InsertEndpoint[T]) ToSQL(dialect Dialect) (string, []any, error) { return toSQL(s, dialect) }
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s InsertEndpoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s BatchInsertEndpoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+// ToSQL returns the query and args the endpoint compiles to, with placeholders rebound for dialect.
+func (s BatchInsertEndpoint[T]) ToSQL(dialect Dialect) (string, []any, error) {
+	return toSQL(s, dialect)
+}
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s BatchInsertEndpoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s UpsertEndpoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+// ToSQL returns the query and args the endpoint compiles to, with placeholders rebound for dialect.
+func (s UpsertEndpoint[T]) ToSQL(dialect Dialect) (string, []any, error) { return toSQL(s, dialect) }
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s UpsertEndpoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s BatchUpsertEndpoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+// ToSQL returns the query and args the endpoint compiles to, with placeholders rebound for dialect.
+func (s BatchUpsertEndpoint[T]) ToSQL(dialect Dialect) (string, []any, error) {
+	return toSQL(s, dialect)
+}
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s BatchUpsertEndpoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s DeleteEndPoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+// ToSQL returns the query and args the endpoint compiles to, with placeholders rebound for dialect.
+func (s DeleteEndPoint[T]) ToSQL(dialect Dialect) (string, []any, error) { return toSQL(s, dialect) }
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s DeleteEndPoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
+
+func (s AggregateEndPoint[T]) buildStatement(dialect Dialect) (string, []any, error) {
+	return s.point2Sql(dialect)
+}
+
+// ToSQL returns the query and args Aggregate runs, with placeholders rebound for dialect.
+func (s AggregateEndPoint[T]) ToSQL(dialect Dialect) (string, []any, error) { return toSQL(s, dialect) }
+
+// Explain renders the query with args interpolated, for logs and golden tests only.
+func (s AggregateEndPoint[T]) Explain(dialect Dialect) (string, error) { return explain(s, dialect) }
@@ -0,0 +1,138 @@
+package db_dao
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- tosql_test.go: Tests for the exported ToSQL / Explain ---
+
+func TestToSQL(t *testing.T) {
+	t.Run("select rebinds for each dialect", func(t *testing.T) {
+		ep := SelectEndPoint[struct{}]{
+			Table:      "users",
+			Fields:     []string{"id", "name"},
+			Conditions: map[string]any{"age": Gt(18), "name": Like("A%")},
+		}
+		query, args, err := ep.ToSQL(Postgres)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT "id","name" FROM "users" WHERE ("age" > $1) AND ("name" LIKE $2)`, query)
+		assert.Equal(t, []any{18, "A%"}, args)
+
+		query, _, err = ep.ToSQL(SQLServer)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT [id],[name] FROM [users] WHERE ([age] > @p1) AND ([name] LIKE @p2)`, query)
+
+		query, _, err = ep.ToSQL(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT `id`,`name` FROM `users` WHERE (`age` > ?) AND (`name` LIKE ?)", query)
+	})
+
+	t.Run("update merges set and where args", func(t *testing.T) {
+		query, args, err := UpdateEndPoint[struct{}]{
+			Table:      "users",
+			Rows:       map[string]any{"age": 31, "name": "Alice"},
+			Conditions: map[string]any{"id": Eq(1)},
+		}.ToSQL(Postgres)
+		require.NoError(t, err)
+		assert.Equal(t, `UPDATE "users" SET "age" = $1,"name" = $2 WHERE ("id" = $3)`, query)
+		assert.Equal(t, []any{31, "Alice", 1}, args)
+	})
+
+	t.Run("page and count", func(t *testing.T) {
+		ep := PageEndPoint[struct{}]{
+			Table:      "users",
+			Conditions: map[string]any{"age": Gte(18)},
+			SortKeys:   []SortKey{{Column: "id"}},
+			PageNo:     2,
+			PageSize:   10,
+			Fields:     []string{"id"},
+		}
+		query, args, err := ep.ToSQL(SQLServer)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT [id] FROM [users] WHERE ([age] >= @p1) ORDER BY [id] ASC OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY`, query)
+		assert.Equal(t, []any{18}, args)
+
+		query, args, err = ep.ToCountSQL(Postgres)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT COUNT(*) FROM "users" WHERE ("age" >= $1)`, query)
+		assert.Equal(t, []any{18}, args)
+	})
+
+	t.Run("cursor page renders the first page", func(t *testing.T) {
+		query, _, err := CursorPageEndPoint[struct{}]{
+			Table:    "users",
+			SortKeys: []SortKey{{Column: "id", Desc: true}},
+			Limit:    20,
+			Cursor:   "ignored",
+			Fields:   []string{"id"},
+		}.ToSQL(Postgres)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT "id" FROM "users" ORDER BY "id" DESC LIMIT 21 OFFSET 0`, query)
+	})
+
+	t.Run("upsert uses the dialect conflict clause", func(t *testing.T) {
+		query, args, err := UpsertEndpoint[struct{}]{
+			Table:           "users",
+			Rows:            map[string]any{"id": 1, "name": "Alice"},
+			ConflictColumns: []string{"id"},
+		}.ToSQL(Postgres)
+		require.NoError(t, err)
+		assert.Equal(t, `INSERT INTO "users" ("id","name") VALUES ($1,$2) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`, query)
+		assert.Equal(t, []any{1, "Alice"}, args)
+	})
+
+	t.Run("errors are returned", func(t *testing.T) {
+		_, _, err := DeleteEndPoint[struct{}]{Table: "users"}.ToSQL(Postgres)
+		assert.EqualError(t, err, "empty conditions for delete")
+		_, err = DeleteEndPoint[struct{}]{Table: "users"}.Explain(Postgres)
+		assert.EqualError(t, err, "empty conditions for delete")
+	})
+}
+
+func TestExplain(t *testing.T) {
+	t.Run("interpolates literals", func(t *testing.T) {
+		at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+		out, err := BatchInsertEndpoint[struct{}]{
+			Table: "posts",
+			Rows: []map[string]any{
+				{"title": "it's", "published": true, "created_at": at, "body": nil},
+				{"title": "x", "published": false, "created_at": sql.NullTime{}, "body": []byte{0xca, 0xfe}},
+			},
+		}.Explain(Postgres)
+		require.NoError(t, err)
+		assert.Equal(t, explainHeader+
+			`INSERT INTO "posts" ("body","created_at","published","title") VALUES `+
+			"(NULL,'2024-05-06T07:08:09Z',TRUE,'it''s'),(x'cafe',NULL,FALSE,'x')", out)
+	})
+
+	t.Run("redacted values stay hidden", func(t *testing.T) {
+		out, err := GetEndPoint[struct{}]{
+			Table:      "users",
+			Conditions: map[string]any{"email": Eq(redacted{"a@example.com"}), "id": In(1, 2)},
+		}.Explain(MySQL)
+		require.NoError(t, err)
+		assert.Equal(t, explainHeader+"SELECT * FROM `users` WHERE (`email` = '[REDACTED]') AND (`id` IN (1, 2))", out)
+	})
+
+	t.Run("placeholders inside quotes are kept", func(t *testing.T) {
+		out, err := SelectEndPoint[struct{}]{
+			Table:      "users",
+			Conditions: map[string]any{"name = ": "Bob"},
+			Appends:    []string{"AND note <> 'why?'"},
+		}.Explain(nil)
+		require.NoError(t, err)
+		assert.Equal(t, explainHeader+"SELECT * FROM `users` WHERE (name = 'Bob') AND note <> 'why?'", out)
+	})
+
+	t.Run("placeholder count mismatch", func(t *testing.T) {
+		_, err := interpolate(SQLite, "SELECT ?", nil)
+		assert.Error(t, err)
+		_, err = interpolate(SQLite, "SELECT 1", []any{1})
+		assert.Error(t, err)
+	})
+}
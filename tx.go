@@ -0,0 +1,161 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrTxUnsupported is returned by WithTx when the executor can neither begin nor join a transaction.
+var ErrTxUnsupported = errors.New("transactions require a *sqlx.DB or *sqlx.Tx executor")
+
+// TxOptions configures WithTx. A nil *TxOptions uses the driver's defaults and does not retry.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+
+	MaxRetries int              // MaxRetries 可重试的错误发生后最多再执行 fn 的次数，0 表示不重试
+	Backoff    time.Duration    // Backoff 第一次重试前的等待时间，之后每次翻倍，默认 50ms
+	MaxBackoff time.Duration    // MaxBackoff 单次等待的上限，默认 2s
+	Retryable  func(error) bool // Retryable 判断错误是否可重试，默认 IsRetryable
+}
+
+const (
+	defaultTxBackoff    = 50 * time.Millisecond
+	defaultTxMaxBackoff = 2 * time.Second
+)
+
+// retryable 判断 err 是否值得重试
+func (o *TxOptions) retryable(err error) bool {
+	if o.Retryable != nil {
+		return o.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// backoff 返回第 attempt 次重试前的等待时间：指数增长并在后一半区间内随机抖动，避免冲突的事务同时重试
+func (o *TxOptions) backoff(attempt int) time.Duration {
+	base, limit := o.Backoff, o.MaxBackoff
+	if base <= 0 {
+		base = defaultTxBackoff
+	}
+	if limit <= 0 {
+		limit = defaultTxMaxBackoff
+	}
+	wait := base
+	for i := 0; i < attempt && wait < limit; i++ {
+		wait *= 2
+	}
+	wait = min(wait, limit)
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// IsRetryable reports whether err is a serialization failure or deadlock, after which the whole
+// transaction can be run again: SQLSTATE 40001 / 40P01 (PostgreSQL), error 1213 / 1205 (MySQL),
+// error 1205 (SQL Server) and "database is locked" (SQLite).
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	// pgx 的 *pgconn.PgError 与 lib/pq 的 *pq.Error 都实现了 SQLState
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		switch state.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+	// go-mssqldb 的 mssql.Error
+	var number interface{ SQLErrorNumber() int32 }
+	if errors.As(err, &number) && number.SQLErrorNumber() == 1205 {
+		return true
+	}
+	// go-sql-driver/mysql 与 go-sqlite3 的错误只能按消息识别
+	msg := err.Error()
+	for _, s := range []string{"Error 1213", "Error 1205", "database is locked"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithTx runs fn in a transaction begun on db, for work that spans several DAOs:
+//
+//	err := db_dao.WithTx(ctx, db, nil, func(tx *sqlx.Tx) error {
+//		if _, err := db_dao.NewDAO[Order](tx).Insert(ctx, ...); err != nil {
+//			return err
+//		}
+//		_, err := db_dao.NewDAO[Stock](tx).Update(ctx, ...)
+//		return err
+//	})
+//
+// The transaction is committed when fn returns nil and rolled back when it returns an error or panics;
+// a panic is propagated after the rollback. With opts.MaxRetries > 0, fn is run again in a new
+// transaction after a retryable error (see IsRetryable), so it must not have side effects outside
+// the database. When db is already a *sqlx.Tx, fn runs in a savepoint of it, like DAO.WithTx
+// and RunInTx: an error or panic undoes only the work of fn, and opts are ignored.
+func WithTx(ctx context.Context, db Executor, opts *TxOptions, fn func(tx *sqlx.Tx) error) error {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	switch db := db.(type) {
+	case *sqlx.Tx:
+		unit, err := Begin(ctx, db, nil)
+		if err != nil {
+			return err
+		}
+		return finishTx(unit, func() error { return fn(db) })
+	case *sqlx.DB:
+		for attempt := 0; ; attempt++ {
+			err := runTx(ctx, db, opts, fn)
+			if err == nil || attempt >= opts.MaxRetries || !opts.retryable(err) {
+				return err
+			}
+			timer := time.NewTimer(opts.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(err, ctx.Err())
+			case <-timer.C:
+			}
+		}
+	}
+	return ErrTxUnsupported
+}
+
+// runTx 执行一次事务
+func runTx(ctx context.Context, db *sqlx.DB, opts *TxOptions, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+	return finishTx(tx, func() error { return fn(tx) })
+}
+
+// WithTx runs fn with a DAO bound to a new transaction; see the package-level WithTx for
+// commit, rollback and retry behaviour. The transactional DAO shares the options and hooks of d.
+// When d is already a transaction, fn runs in a savepoint (see BeginTx): an error or panic undoes
+// only the work of fn, and opts are ignored.
+//
+//	err := userDAO.WithTx(ctx, &db_dao.TxOptions{MaxRetries: 3}, func(tx db_dao.IDAO[User]) error {
+//		_, err := tx.UpdateByID(ctx, 1, map[string]any{"age": 31})
+//		return err
+//	})
+func (d *DAO[T]) WithTx(ctx context.Context, opts *TxOptions, fn func(tx IDAO[T]) error) error {
+	if _, ok := d.db.(*sqlx.Tx); ok {
+		nested, err := d.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		return finishTx(nested, func() error { return fn(nested) })
+	}
+	return WithTx(ctx, d.db, opts, func(tx *sqlx.Tx) error {
+		return fn(&DAO[T]{db: tx, cfg: d.cfg, scope: d.scope, txn: newTxLevel()})
+	})
+}
@@ -0,0 +1,179 @@
+package db_dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- tx_test.go: Tests for WithTx ---
+
+func userAge(t *testing.T, dao IDAO[User], id int64) int {
+	u, err := dao.FindByID(context.Background(), id)
+	require.NoError(t, err)
+	return u.Age
+}
+
+func TestWithTx_CommitAndRollback(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[User](newSQLiteDB(t, createUsersTable, seedUsers))
+
+	err := dao.WithTx(ctx, nil, func(tx IDAO[User]) error {
+		_, err := tx.UpdateByID(ctx, 1, map[string]any{"age": 31})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 31, userAge(t, dao, 1))
+
+	errBoom := errors.New("boom")
+	err = dao.WithTx(ctx, nil, func(tx IDAO[User]) error {
+		if _, err := tx.UpdateByID(ctx, 1, map[string]any{"age": 99}); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	assert.ErrorIs(t, err, errBoom)
+	assert.Equal(t, 31, userAge(t, dao, 1))
+}
+
+func TestWithTx_Panic(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[User](newSQLiteDB(t, createUsersTable, seedUsers))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = dao.WithTx(ctx, nil, func(tx IDAO[User]) error {
+			if _, err := tx.UpdateByID(ctx, 1, map[string]any{"age": 99}); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	})
+	// 回滚后连接被释放，后续查询不会阻塞
+	assert.Equal(t, 30, userAge(t, dao, 1))
+}
+
+func TestWithTx_Retry(t *testing.T) {
+	ctx := context.Background()
+	dao := NewDAO[User](newSQLiteDB(t, createUsersTable, seedUsers))
+	errConflict := errors.New("conflict")
+	opts := &TxOptions{
+		MaxRetries: 3,
+		Backoff:    time.Millisecond,
+		Retryable:  func(err error) bool { return errors.Is(err, errConflict) },
+	}
+
+	attempts := 0
+	err := dao.WithTx(ctx, opts, func(tx IDAO[User]) error {
+		attempts++
+		if _, err := tx.UpdateByID(ctx, 2, map[string]any{"age": 40 + attempts}); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return errConflict
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 43, userAge(t, dao, 2))
+
+	// 超过 MaxRetries 后返回最后一次的错误
+	attempts = 0
+	err = dao.WithTx(ctx, opts, func(IDAO[User]) error {
+		attempts++
+		return errConflict
+	})
+	assert.ErrorIs(t, err, errConflict)
+	assert.Equal(t, 4, attempts)
+
+	// 不可重试的错误只执行一次
+	attempts = 0
+	errOther := errors.New("other")
+	err = dao.WithTx(ctx, opts, func(IDAO[User]) error {
+		attempts++
+		return errOther
+	})
+	assert.ErrorIs(t, err, errOther)
+	assert.Equal(t, 1, attempts)
+
+	// 等待重试时 ctx 被取消
+	cancelCtx, cancel := context.WithCancel(ctx)
+	err = dao.WithTx(cancelCtx, &TxOptions{MaxRetries: 1, Backoff: time.Hour, Retryable: opts.Retryable}, func(IDAO[User]) error {
+		cancel()
+		return errConflict
+	})
+	assert.ErrorIs(t, err, errConflict)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWithTx_PackageLevel(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, createUsersTable, seedUsers)
+
+	err := WithTx(ctx, db, nil, func(tx *sqlx.Tx) error {
+		users := NewDAO[User](tx)
+		if _, err := users.UpdateByID(ctx, 1, map[string]any{"age": 50}); err != nil {
+			return err
+		}
+		// 已经处于事务中时在保存点中运行，失败只撤销自己的修改
+		err := WithTx(ctx, tx, nil, func(inner *sqlx.Tx) error {
+			assert.Same(t, tx, inner)
+			if _, err := NewDAO[User](inner).UpdateByID(ctx, 1, map[string]any{"age": 99}); err != nil {
+				return err
+			}
+			return errors.New("abort")
+		})
+		assert.EqualError(t, err, "abort")
+		assert.Equal(t, 50, userAge(t, users, 1))
+		return WithTx(ctx, tx, nil, func(inner *sqlx.Tx) error {
+			_, err := NewDAO[User](inner).UpdateByID(ctx, 2, map[string]any{"age": 50})
+			return err
+		})
+	})
+	require.NoError(t, err)
+	dao := NewDAO[User](db)
+	assert.Equal(t, 50, userAge(t, dao, 1))
+	assert.Equal(t, 50, userAge(t, dao, 2))
+
+	err = WithTx(ctx, nil, nil, func(*sqlx.Tx) error { return nil })
+	assert.ErrorIs(t, err, ErrTxUnsupported)
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+type sqlServerError int32
+
+func (e sqlServerError) Error() string         { return fmt.Sprintf("mssql: error %d", int32(e)) }
+func (e sqlServerError) SQLErrorNumber() int32 { return int32(e) }
+
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, IsRetryable(nil))
+	assert.True(t, IsRetryable(sqlStateError("40001")))
+	assert.True(t, IsRetryable(fmt.Errorf("update: %w", sqlStateError("40P01"))))
+	assert.False(t, IsRetryable(sqlStateError("23505")))
+	assert.True(t, IsRetryable(sqlServerError(1205)))
+	assert.False(t, IsRetryable(sqlServerError(2627)))
+	assert.True(t, IsRetryable(errors.New("Error 1213 (40001): Deadlock found when trying to get lock; try restarting transaction")))
+	assert.True(t, IsRetryable(errors.New("database is locked")))
+	assert.False(t, IsRetryable(errors.New("no such table: users")))
+}
+
+func TestTxOptionsBackoff(t *testing.T) {
+	opts := &TxOptions{Backoff: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond}
+	for attempt, limit := range []time.Duration{10, 20, 40, 40} {
+		limit *= time.Millisecond
+		wait := opts.backoff(attempt)
+		assert.GreaterOrEqual(t, wait, limit/2)
+		assert.LessOrEqual(t, wait, limit)
+	}
+	assert.LessOrEqual(t, (&TxOptions{}).backoff(0), defaultTxBackoff)
+}
@@ -0,0 +1,147 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Tx is a unit of work: one transaction shared by the DAOs of different model types handed out by Use.
+// Commit or Rollback on the Tx, or on any of its DAOs, ends the transaction for all of them.
+//
+//	tx, err := db_dao.Begin(ctx, db, nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer tx.Rollback() // 已提交时返回 sql.ErrTxDone，可以忽略
+//	if _, err := db_dao.Use[User](tx).Insert(ctx, ...); err != nil {
+//		return err
+//	}
+//	if _, err := db_dao.Use[Order](tx).BatchInsert(ctx, ...); err != nil {
+//		return err
+//	}
+//	return tx.Commit()
+type Tx struct {
+	tx    *sqlx.Tx
+	cfg   *config
+	level *txLevel
+}
+
+// Begin starts a unit of work on db. When db is already a *sqlx.Tx, the unit of work runs in a savepoint of it.
+//
+// opts configure what every DAO handed out by Use shares: WithDialect, WithHooks, WithSensitiveColumns,
+// WithCursorSecret, WithClock and WithNamingStrategy. Model-level options (WithSoftDelete, WithTimestamps,
+// WithColumnValidation, WithStrictMode) are ignored here; pass them to Use.
+func Begin(ctx context.Context, db Executor, txOpts *sql.TxOptions, opts ...Option) (*Tx, error) {
+	cfg := newConfig(db, opts).executorConfig()
+	switch db := db.(type) {
+	case *sqlx.DB:
+		tx, err := db.BeginTxx(ctx, txOpts)
+		if err != nil {
+			return nil, err
+		}
+		return &Tx{tx: tx, cfg: cfg, level: newTxLevel()}, nil
+	case *sqlx.Tx:
+		level, err := beginLevel(ctx, db, cfg.dialect, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &Tx{tx: db, cfg: cfg, level: level}, nil
+	}
+	return nil, ErrTxUnsupported
+}
+
+// TxOf returns the unit of work of a transactional DAO, e.g. one returned by BeginTx or passed to
+// a WithTx callback, so DAOs of other model types can join its transaction:
+//
+//	err := userDAO.WithTx(ctx, nil, func(users db_dao.IDAO[User]) error {
+//		tx, err := db_dao.TxOf(users)
+//		if err != nil {
+//			return err
+//		}
+//		...
+//		_, err = db_dao.Use[Order](tx).Insert(ctx, ...)
+//		return err
+//	})
+//
+// The DAOs handed out by the returned Tx share the executor-level options of d (see Begin);
+// its model-level options such as soft deletion do not carry over to other model types.
+func TxOf[T any](dao IDAO[T]) (*Tx, error) {
+	d, err := daoOf(dao)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := d.db.(*sqlx.Tx)
+	if !ok {
+		return nil, errors.New("TxOf requires a transactional DAO")
+	}
+	return &Tx{tx: tx, cfg: d.cfg.executorConfig(), level: d.txn}, nil
+}
+
+// Use returns a DAO for T that runs in the transaction of tx. opts are applied on top of the
+// options of tx and only affect the returned DAO:
+//
+//	posts := db_dao.Use[Post](tx, db_dao.WithSoftDelete("deleted_at"), db_dao.WithStrictMode())
+func Use[T any](tx *Tx, opts ...Option) IDAO[T] {
+	cfg := tx.cfg
+	if len(opts) > 0 {
+		cfg = tx.cfg.executorConfig()
+		for _, opt := range opts {
+			opt(cfg)
+		}
+	}
+	return &DAO[T]{db: tx.tx, cfg: cfg, txn: tx.level}
+}
+
+// Begin starts a nested unit of work in a savepoint; see DAO.BeginTx.
+func (t *Tx) Begin(ctx context.Context) (*Tx, error) {
+	level, err := beginLevel(ctx, t.tx, t.cfg.dialect, t.level)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: t.tx, cfg: t.cfg, level: level}, nil
+}
+
+// Commit commits the transaction, or releases the savepoint of a nested unit of work.
+func (t *Tx) Commit() error {
+	return endLevel(context.Background(), t.tx, t.cfg.dialect, t.level, false)
+}
+
+// Rollback rollbacks the transaction, or rolls back to the savepoint of a nested unit of work.
+func (t *Tx) Rollback() error {
+	return endLevel(context.Background(), t.tx, t.cfg.dialect, t.level, true)
+}
+
+// GetExecutor returns the underlying *sqlx.Tx.
+func (t *Tx) GetExecutor() Executor {
+	return t.tx
+}
+
+// RunInTx runs fn in a unit of work on db with the commit, rollback and retry behaviour of WithTx.
+// When db is already a *sqlx.Tx, fn runs in a savepoint of it.
+//
+//	err := db_dao.RunInTx(ctx, db, nil, func(tx *db_dao.Tx) error {
+//		if _, err := db_dao.Use[User](tx).Insert(ctx, ...); err != nil {
+//			return err
+//		}
+//		_, err := db_dao.Use[Order](tx).BatchInsert(ctx, ...)
+//		return err
+//	})
+//
+// options are the executor-level options of Begin.
+func RunInTx(ctx context.Context, db Executor, opts *TxOptions, fn func(tx *Tx) error, options ...Option) error {
+	cfg := newConfig(db, options).executorConfig()
+	if tx, ok := db.(*sqlx.Tx); ok {
+		level, err := beginLevel(ctx, tx, cfg.dialect, nil)
+		if err != nil {
+			return err
+		}
+		unit := &Tx{tx: tx, cfg: cfg, level: level}
+		return finishTx(unit, func() error { return fn(unit) })
+	}
+	return WithTx(ctx, db, opts, func(tx *sqlx.Tx) error {
+		return fn(&Tx{tx: tx, cfg: cfg, level: newTxLevel()})
+	})
+}
@@ -0,0 +1,177 @@
+package db_dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- unitofwork_test.go: Tests for Tx / Use ---
+
+type order struct {
+	ID     int64 `db:"id"`
+	UserID int64 `db:"user_id"`
+	Total  int   `db:"total"`
+}
+
+// unitSchema 在 users 表之外创建 orders 表
+var unitSchema = []string{
+	createUsersTable,
+	seedUsers,
+	`CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER, total INTEGER)`,
+}
+
+func orderCount(t *testing.T, db *sqlx.DB) int64 {
+	n, err := NewDAO[order](db).Count(context.Background(), AggregateEndPoint[order]{})
+	require.NoError(t, err)
+	return n
+}
+
+func TestTx_UseCommit(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, unitSchema...)
+
+	tx, err := Begin(ctx, db, nil)
+	require.NoError(t, err)
+	_, err = Use[User](tx).Insert(ctx, InsertEndpoint[User]{Rows: map[string]any{"id": 3, "name": "Carol", "age": 20}})
+	require.NoError(t, err)
+	orders := Use[order](tx)
+	_, err = orders.Insert(ctx, InsertEndpoint[order]{Rows: map[string]any{"id": 1, "user_id": 3, "total": 100}})
+	require.NoError(t, err)
+
+	// 任意一个 DAO 的 Commit 都会提交整个事务
+	require.NoError(t, orders.Commit())
+	assert.ErrorIs(t, tx.Commit(), sql.ErrTxDone)
+	assert.ErrorIs(t, tx.Rollback(), sql.ErrTxDone)
+
+	assert.Equal(t, int64(1), orderCount(t, db))
+	assert.Equal(t, 20, userAge(t, NewDAO[User](db), 3))
+}
+
+func TestTx_Rollback(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, unitSchema...)
+
+	tx, err := Begin(ctx, db, nil)
+	require.NoError(t, err)
+	_, err = Use[User](tx).UpdateByID(ctx, 1, map[string]any{"age": 99})
+	require.NoError(t, err)
+
+	// 嵌套的工作单元回滚只撤销自己的修改
+	nested, err := tx.Begin(ctx)
+	require.NoError(t, err)
+	_, err = Use[order](nested).Insert(ctx, InsertEndpoint[order]{Rows: map[string]any{"id": 1, "user_id": 1, "total": 10}})
+	require.NoError(t, err)
+	require.NoError(t, nested.Rollback())
+	n, err := Use[order](tx).Count(ctx, AggregateEndPoint[order]{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	require.NoError(t, tx.Rollback())
+	assert.Equal(t, 30, userAge(t, NewDAO[User](db), 1))
+}
+
+func TestTxOf(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, unitSchema...)
+	users := NewDAO[User](db)
+
+	err := users.WithTx(ctx, nil, func(userTx IDAO[User]) error {
+		if _, err := userTx.UpdateByID(ctx, 1, map[string]any{"age": 31}); err != nil {
+			return err
+		}
+		tx, err := TxOf(userTx)
+		if err != nil {
+			return err
+		}
+		_, err = Use[order](tx).Insert(ctx, InsertEndpoint[order]{Rows: map[string]any{"id": 1, "user_id": 1, "total": 10}})
+		if err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	assert.EqualError(t, err, "abort")
+	assert.Equal(t, int64(0), orderCount(t, db))
+	assert.Equal(t, 30, userAge(t, users, 1))
+
+	_, err = TxOf[User](users)
+	assert.EqualError(t, err, "TxOf requires a transactional DAO")
+}
+
+func TestTxOf_ModelOptions(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, unitSchema...)
+	_, err := db.Exec(`ALTER TABLE users ADD COLUMN deleted_at DATETIME`)
+	require.NoError(t, err)
+	users := NewDAO[User](db, WithSoftDelete("deleted_at"), WithStrictMode())
+	appends := []string{"ORDER BY id"}
+
+	err = users.WithTx(ctx, nil, func(userTx IDAO[User]) error {
+		tx, err := TxOf(userTx)
+		require.NoError(t, err)
+
+		// 软删除与严格模式属于 User，不会传递给 order (orders 表没有 deleted_at 列)
+		orders := Use[order](tx)
+		_, err = orders.Insert(ctx, InsertEndpoint[order]{Rows: map[string]any{"id": 1, "user_id": 1, "total": 10}})
+		require.NoError(t, err)
+		n, err := orders.Count(ctx, AggregateEndPoint[order]{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		var list []order
+		require.NoError(t, orders.Select(ctx, SelectEndPoint[order]{Model: &list, Appends: appends}))
+
+		// Use 的 Option 只作用于返回的 DAO
+		err = Use[order](tx, WithStrictMode()).Select(ctx, SelectEndPoint[order]{Model: &list, Appends: appends})
+		assert.ErrorIs(t, err, ErrAppendsNotAllowed)
+		assert.NoError(t, Use[order](tx).Select(ctx, SelectEndPoint[order]{Model: &list, Appends: appends}))
+
+		_, err = Use[User](tx, WithSoftDelete("deleted_at")).DeleteByID(ctx, 2)
+		require.NoError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), orderCount(t, db))
+	_, err = users.FindByID(ctx, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, 40, userAge(t, users.WithTrashed(), 2))
+}
+
+func TestRunInTx(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, unitSchema...)
+
+	err := RunInTx(ctx, db, nil, func(tx *Tx) error {
+		if _, err := Use[User](tx).UpdateByID(ctx, 2, map[string]any{"age": 41}); err != nil {
+			return err
+		}
+		_, err := Use[order](tx).Insert(ctx, InsertEndpoint[order]{Rows: map[string]any{"id": 1, "user_id": 2, "total": 10}})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), orderCount(t, db))
+	assert.Equal(t, 41, userAge(t, NewDAO[User](db), 2))
+
+	// 在已有的事务中运行时使用保存点
+	outer, err := db.Beginx()
+	require.NoError(t, err)
+	err = RunInTx(ctx, outer, nil, func(tx *Tx) error {
+		assert.Same(t, outer, tx.GetExecutor())
+		if _, err := Use[order](tx).Insert(ctx, InsertEndpoint[order]{Rows: map[string]any{"id": 2, "user_id": 2, "total": 20}}); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	assert.EqualError(t, err, "abort")
+	n, err := NewDAO[order](outer).Count(ctx, AggregateEndPoint[order]{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	require.NoError(t, outer.Commit())
+
+	_, err = Begin(ctx, nil, nil)
+	assert.ErrorIs(t, err, ErrTxUnsupported)
+}
@@ -6,27 +6,27 @@ import (
 	"strings"
 )
 
-func (s UpdateEndPoint[T]) point2Sql() (string, []any, []any, error) {
-	tableQuery, err := buildTableClause(s.Table)
+func (s UpdateEndPoint[T]) point2Sql(dialect Dialect) (string, []any, []any, error) {
+	tableQuery, err := buildTableClause(dialect, s.Table)
 	if err != nil {
 		return "", nil, nil, err
 	}
 
-	rowsQuery, rowsArgs, err := buildSetClauseForUpdate(s.Rows)
+	rowsQuery, rowsArgs, err := buildSetClauseForUpdate(dialect, s.Rows)
 	if err != nil {
 		return "", nil, nil, err
 	}
 
-	// For safety, UPDATE must have conditions
-	if len(s.Conditions) == 0 {
-		return "", nil, nil, errors.New("empty conditions for update")
-	}
-
-	conditionsQuery, conditionsArgs, err := buildWhereClause(s.Conditions)
+	conditionsQuery, conditionsArgs, err := buildWhereClause(dialect, s.Conditions)
 	if err != nil {
 		return "", nil, nil, err
 	}
 
+	// For safety, UPDATE must have conditions
+	if conditionsQuery == "" {
+		return "", nil, nil, errors.New("empty conditions for update")
+	}
+
 	appendsQuery := buildAppendsClause(s.Appends)
 
 	var queryBuilder strings.Builder
@@ -0,0 +1,61 @@
+package db_dao
+
+// validateUpsertColumns 冲突列与更新列会原样写入 ON CONFLICT / DO UPDATE SET / ON DUPLICATE KEY UPDATE，必须是合法列名
+func validateUpsertColumns(conflictColumns, updateColumns []string) error {
+	for _, columns := range [][]string{conflictColumns, updateColumns} {
+		for _, c := range columns {
+			if err := validateIdentifier(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s UpsertEndpoint[T]) point2Sql(dialect Dialect) (string, []any, error) {
+	if err := validateUpsertColumns(s.ConflictColumns, s.UpdateColumns); err != nil {
+		return "", nil, err
+	}
+	query, args, err := InsertEndpoint[T]{Table: s.Table, Rows: s.Rows}.point2Sql(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	conflictQuery, err := dialect.Upsert(sortedKeys(s.Rows), s.ConflictColumns, s.UpdateColumns, s.DoNothing)
+	if err != nil {
+		return "", nil, err
+	}
+	return query + " " + conflictQuery, args, nil
+}
+
+func (s BatchUpsertEndpoint[T]) point2Sql(dialect Dialect) (string, []any, error) {
+	if err := validateUpsertColumns(s.ConflictColumns, s.UpdateColumns); err != nil {
+		return "", nil, err
+	}
+	query, args, err := BatchInsertEndpoint[T]{Table: s.Table, Rows: s.Rows}.point2Sql(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	conflictQuery, err := dialect.Upsert(sortedKeys(s.Rows[0]), s.ConflictColumns, s.UpdateColumns, s.DoNothing)
+	if err != nil {
+		return "", nil, err
+	}
+	return query + " " + conflictQuery, args, nil
+}
+
+// excludeColumns 返回 columns 中不属于 excluded 的列，保持原有顺序
+func excludeColumns(columns, excluded []string) []string {
+	var result []string
+	for _, c := range columns {
+		skip := false
+		for _, e := range excluded {
+			if c == e {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			result = append(result, c)
+		}
+	}
+	return result
+}